@@ -12,9 +12,11 @@ import (
 	"task-pool/internal/domain/entity"
 	"task-pool/internal/entrypoint"
 	"task-pool/internal/entrypoint/handler"
+	"task-pool/internal/event"
 	"task-pool/internal/service"
 	"task-pool/internal/worker"
 	"task-pool/pkg/logger"
+	"task-pool/pkg/validator"
 
 	"github.com/gofiber/fiber/v3"
 	"github.com/spf13/cobra"
@@ -22,6 +24,10 @@ import (
 	"gorm.io/gorm"
 )
 
+// eventBuffer is how many events a streaming client may fall behind before
+// it is disconnected.
+const eventBuffer = 256
+
 func runHTTPServerCMD() *cobra.Command {
 	return &cobra.Command{
 		Use:   "http",
@@ -37,7 +43,9 @@ func runHTTPServerCMD() *cobra.Command {
 }
 
 func runHTTPServer(cfg config.Config) error {
-	app := fiber.New()
+	app := fiber.New(fiber.Config{
+		StructValidator: validator.New(),
+	})
 
 	// Bootstrap the application
 	bootstrapResult, bErr := bootstrap(app, cfg)
@@ -45,7 +53,7 @@ func runHTTPServer(cfg config.Config) error {
 		return bErr
 	}
 
-	shutdown(app, bootstrapResult, cfg)
+	shutdownDone := shutdown(app, bootstrapResult, cfg)
 	logger.Info("Starting HTTP server on port").WithInt("port", cfg.Server.Port).Log()
 
 	aErr := app.Listen(fmt.Sprintf(":%d", cfg.Server.Port))
@@ -53,14 +61,19 @@ func runHTTPServer(cfg config.Config) error {
 		logger.Error("Failed to start HTTP server").WithError(aErr).Log()
 		return fmt.Errorf("failed to start HTTP server: %w", aErr)
 	}
+
+	// Listen returns as soon as the server stops accepting connections, wait
+	// for the workers to drain before the process exits.
+	<-shutdownDone
 	logger.Info("Graceful shutdown completed").Log()
 
 	return nil
 }
 
 type bootstrapResult struct {
-	taskWorker  worker.Worker[*entity.Task]
-	taskChannel chan *entity.Task
+	taskWorker     worker.Worker[*entity.Task]
+	scheduleRunner *worker.ScheduleRunner
+	bus            *event.Bus
 }
 
 func bootstrap(app *fiber.App, cfg config.Config) (*bootstrapResult, error) {
@@ -69,32 +82,57 @@ func bootstrap(app *fiber.App, cfg config.Config) (*bootstrapResult, error) {
 		return nil, fmt.Errorf("failed to setup database: %w", err)
 	}
 
-	// Initialize channel
-	taskChannel := make(chan *entity.Task, cfg.TaskWorker.QueueSize)
+	// Initialize wakeup channels, the tasks table itself is the queue
+	wakeup := worker.NewWakeup(cfg.TaskWorker)
+
+	// Initialize the event bus, it streams the task changes of this process
+	bus := event.NewBus(eventBuffer)
 
 	// Initialize repository
 	taskRepository := postgresrepo.NewTaskRepository(db)
+	attemptRepository := postgresrepo.NewTaskAttemptRepository(db)
+	scheduleRepository := postgresrepo.NewScheduleRepository(db)
+	workflowRepository := postgresrepo.NewWorkflowRepository(db)
 
 	// Initialize service
-	taskService := service.NewTaskService(taskRepository, taskChannel)
+	taskService := service.NewTaskService(taskRepository, attemptRepository, wakeup, cfg.TaskWorker, bus)
+	scheduleService := service.NewScheduleService(scheduleRepository)
+	workflowService := service.NewWorkflowService(workflowRepository, taskRepository, wakeup, cfg.TaskWorker, bus)
 
 	// Initialize handler
 	taskHandler := handler.NewTaskHandler(taskService)
+	scheduleHandler := handler.NewScheduleHandler(scheduleService)
+	workflowHandler := handler.NewWorkflowHandler(workflowService)
+	eventHandler := handler.NewEventHandler(taskService, bus)
+	webSocketHandler := handler.NewWebSocketHandler(bus, cfg.Server)
 
 	// Register handlers
 	entrypoint.RegisterHttpHandlers(app, entrypoint.HandlerOptions{
-		TaskHandler: taskHandler,
+		TaskHandler:     taskHandler,
+		ScheduleHandler: scheduleHandler,
+		WorkflowHandler: workflowHandler,
+		EventHandler:    eventHandler,
+
+		WebSocketHandler: webSocketHandler,
 	})
 
+	// Register task handlers
+	worker.Register(worker.TaskTypeSleep, worker.SleepHandler)
+
 	// Initialize worker
-	taskWorker := worker.NewTaskWorker(taskRepository, uint64(cfg.TaskWorker.Workers), taskChannel)
+	taskWorker := worker.NewTaskWorker(taskRepository, attemptRepository, worker.DefaultRegistry(), cfg.TaskWorker, wakeup, bus)
+
+	// Initialize schedule runner
+	scheduleRunner := worker.NewScheduleRunner(scheduleRepository, cfg.TaskWorker, wakeup, bus)
 
-	// Start worker with context
+	// Start worker and schedule runner with context
 	taskWorker.Run(context.Background())
+	scheduleRunner.Run(context.Background())
 
 	return &bootstrapResult{
-		taskWorker:  taskWorker,
-		taskChannel: taskChannel,
+		taskWorker:     taskWorker,
+		scheduleRunner: scheduleRunner,
+		bus:            bus,
 	}, nil
 }
 
@@ -107,7 +145,7 @@ func setupDB(cfg config.Config) (*gorm.DB, error) {
 		cfg.Database.Name,
 		cfg.Database.SSLMode)
 
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{TranslateError: true})
 	if err != nil {
 		logger.Error("Failed to connect to database").WithError(err).Log()
 		return nil, err
@@ -122,7 +160,7 @@ func setupDB(cfg config.Config) (*gorm.DB, error) {
 	sqlDB.SetMaxOpenConns(cfg.Database.MaxOpenConnections)
 
 	// Auto migrate database tables
-	err = db.AutoMigrate(&entity.Task{})
+	err = db.AutoMigrate(&entity.Workflow{}, &entity.Task{}, &entity.TaskDependency{}, &entity.TaskAttempt{}, &entity.Schedule{})
 	if err != nil {
 		logger.Error("Failed to auto migrate database").WithError(err).Log()
 		return nil, fmt.Errorf("failed to auto migrate database: %w", err)
@@ -132,29 +170,39 @@ func setupDB(cfg config.Config) (*gorm.DB, error) {
 	return db, nil
 }
 
-func shutdown(app *fiber.App, bootstrapResult *bootstrapResult, conf config.Config) {
+func shutdown(app *fiber.App, bootstrapResult *bootstrapResult, conf config.Config) <-chan struct{} {
+	done := make(chan struct{})
+
 	sig := make(chan os.Signal, 1)
 	signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
 
 	go func() {
+		defer close(done)
+
 		<-sig
 
 		ctx, cancel := context.WithTimeout(context.Background(), conf.Server.ShutdownTimeout)
 		defer cancel()
 
+		// Event streams only end with their subscription, end them first
+		// so that the server does not wait for them.
+		bootstrapResult.bus.Close()
+
 		if err := app.ShutdownWithContext(ctx); err != nil {
 			log.Printf("Error shutting down server: %v\n", err)
 		}
-
-		bootstrapResult.taskWorker.Shutdown()
-		logger.Info("Worker shutdown successfully").Log()
-
-		close(bootstrapResult.taskChannel)
-		logger.Info("Task channel closed successfully").Log()
-
 		logger.Info("Server shutdown successfully").Log()
 
-		<-ctx.Done()
+		if err := bootstrapResult.scheduleRunner.Shutdown(ctx); err != nil {
+			logger.Error("Schedule runner did not stop in time").WithError(err).Log()
+		}
+
+		if err := bootstrapResult.taskWorker.Shutdown(ctx); err != nil {
+			logger.Error("Worker did not drain in time").WithError(err).Log()
+			return
+		}
+		logger.Info("Worker shutdown successfully").Log()
 	}()
 
+	return done
 }
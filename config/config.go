@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"net/url"
 	"time"
 
 	"github.com/kelseyhightower/envconfig"
@@ -21,6 +22,18 @@ type Server struct {
 	ReadTimeout     time.Duration `envconfig:"SERVER_READ_TIMEOUT" default:"10s"`
 	Debug           bool          `envconfig:"SERVER_DEBUG" default:"false"`
 	ShutdownTimeout time.Duration `envconfig:"SERVER_SHUTDOWN_TIMEOUT" default:"10s"`
+
+	// WebSocketPingInterval is how often the server pings a WebSocket client,
+	// a client that has not answered for two intervals is disconnected.
+	// WebSocketMaxSubscriptions bounds the tasks, queues and statuses a
+	// single connection subscribes to.
+	WebSocketPingInterval     time.Duration `envconfig:"SERVER_WS_PING_INTERVAL" default:"30s"`
+	WebSocketMaxSubscriptions int           `envconfig:"SERVER_WS_MAX_SUBSCRIPTIONS" default:"100"`
+
+	// WebSocketAllowedOrigins lists the origins, e.g. https://dash.example.com,
+	// that may open a WebSocket connection besides the server's own. "*"
+	// allows any origin.
+	WebSocketAllowedOrigins []string `envconfig:"SERVER_WS_ALLOWED_ORIGINS"`
 }
 
 type Database struct {
@@ -34,10 +47,42 @@ type Database struct {
 }
 
 type TaskWorker struct {
-	Workers   int `envconfig:"TASK_WORKER_WORKERS" default:"3"`
-	QueueSize int `envconfig:"TASK_WORKER_QUEUE_SIZE" default:"3"`
+	// Workers is the concurrency of the default queue, Queues adds named
+	// queues with their own concurrency, e.g. "emails:2,reports:1".
+	Workers        int            `envconfig:"TASK_WORKER_WORKERS" default:"3"`
+	Queues         map[string]int `envconfig:"TASK_WORKER_QUEUES"`
+	QueueSize      int            `envconfig:"TASK_WORKER_QUEUE_SIZE" default:"3"`
+	PollInterval   time.Duration  `envconfig:"TASK_WORKER_POLL_INTERVAL" default:"1s"`
+	MaxAttempts    int            `envconfig:"TASK_WORKER_MAX_ATTEMPTS" default:"3"`
+	RetryBaseDelay time.Duration  `envconfig:"TASK_WORKER_RETRY_BASE_DELAY" default:"1s"`
+	RetryMaxDelay  time.Duration  `envconfig:"TASK_WORKER_RETRY_MAX_DELAY" default:"5m"`
+	RetryJitter    float64        `envconfig:"TASK_WORKER_RETRY_JITTER" default:"0.2"`
+	PriorityAging  time.Duration  `envconfig:"TASK_WORKER_PRIORITY_AGING" default:"1m"`
+	// Timeout bounds a single execution of a task unless the task sets its
+	// own, 0 disables it.
+	Timeout time.Duration `envconfig:"TASK_WORKER_TIMEOUT" default:"10m"`
+	// ProgressInterval is the least time between two writes of the progress
+	// reported by a handler, later reports are coalesced into the next write.
+	ProgressInterval time.Duration `envconfig:"TASK_WORKER_PROGRESS_INTERVAL" default:"1s"`
+	// HeartbeatTimeout is how long a queued or running task may go without a
+	// heartbeat from its worker before it is recovered as abandoned, workers
+	// send one every third of it. 0 disables heartbeats and recovery.
+	HeartbeatTimeout time.Duration `envconfig:"TASK_WORKER_HEARTBEAT_TIMEOUT" default:"30s"`
+	// MaxPending bounds the due pending tasks of each queue, 0 disables the
+	// limit. OverflowPolicy decides what happens to new tasks once it is
+	// reached: "block" waits up to OverflowTimeout for room, "reject" fails
+	// right away and "spill" stores the task anyway.
+	MaxPending      int           `envconfig:"TASK_WORKER_MAX_PENDING" default:"0"`
+	OverflowPolicy  string        `envconfig:"TASK_WORKER_OVERFLOW_POLICY" default:"spill"`
+	OverflowTimeout time.Duration `envconfig:"TASK_WORKER_OVERFLOW_TIMEOUT" default:"5s"`
 }
 
+const (
+	OverflowBlock  = "block"
+	OverflowReject = "reject"
+	OverflowSpill  = "spill"
+)
+
 func Load() (*Config, error) {
 	cfg := Config{}
 	err := envconfig.Process("", &cfg)
@@ -45,5 +90,30 @@ func Load() (*Config, error) {
 		return nil, fmt.Errorf("failed to load env variable into config struct: %w", err)
 	}
 
+	switch cfg.TaskWorker.OverflowPolicy {
+	case OverflowBlock, OverflowReject, OverflowSpill:
+	default:
+		return nil, fmt.Errorf("invalid TASK_WORKER_OVERFLOW_POLICY %q", cfg.TaskWorker.OverflowPolicy)
+	}
+
+	if cfg.Server.WebSocketPingInterval <= 0 {
+		return nil, fmt.Errorf("invalid SERVER_WS_PING_INTERVAL %s", cfg.Server.WebSocketPingInterval)
+	}
+
+	if cfg.Server.WebSocketMaxSubscriptions <= 0 {
+		return nil, fmt.Errorf("invalid SERVER_WS_MAX_SUBSCRIPTIONS %d", cfg.Server.WebSocketMaxSubscriptions)
+	}
+
+	for _, origin := range cfg.Server.WebSocketAllowedOrigins {
+		if origin == "*" {
+			continue
+		}
+
+		u, err := url.Parse(origin)
+		if err != nil || u.Scheme == "" || u.Host == "" || u.Path != "" {
+			return nil, fmt.Errorf("invalid SERVER_WS_ALLOWED_ORIGINS origin %q", origin)
+		}
+	}
+
 	return &cfg, nil
 }
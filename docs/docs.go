@@ -15,9 +15,29 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/api/v1/tasks": {
+        "/api/v1/events": {
+            "get": {
+                "description": "Stream the status, progress and result changes of every task as server-sent events",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "events"
+                ],
+                "summary": "Stream events of all tasks",
+                "responses": {
+                    "200": {
+                        "description": "Stream of task events",
+                        "schema": {
+                            "$ref": "#/definitions/task-pool_internal_event.TaskEvent"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/schedules": {
             "get": {
-                "description": "Get a list of all tasks in the system",
+                "description": "Get a list of all schedules in the system",
                 "consumes": [
                     "application/json"
                 ],
@@ -25,16 +45,16 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "tasks"
+                    "schedules"
                 ],
-                "summary": "Get all tasks",
+                "summary": "Get all schedules",
                 "responses": {
                     "200": {
-                        "description": "List of tasks",
+                        "description": "List of schedules",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/task-pool_internal_domain_entity.Task"
+                                "$ref": "#/definitions/task-pool_internal_domain_entity.Schedule"
                             }
                         }
                     },
@@ -50,7 +70,7 @@ const docTemplate = `{
                 }
             },
             "post": {
-                "description": "Create a new task with title and description",
+                "description": "Create a cron schedule that creates a task from its template on every tick",
                 "consumes": [
                     "application/json"
                 ],
@@ -58,28 +78,25 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "tasks"
+                    "schedules"
                 ],
-                "summary": "Create a new task",
+                "summary": "Create a new schedule",
                 "parameters": [
                     {
-                        "description": "Task creation request",
-                        "name": "task",
+                        "description": "Schedule creation request",
+                        "name": "schedule",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/task-pool_internal_service_contracts.CreateTask"
+                            "$ref": "#/definitions/task-pool_internal_service_contracts.CreateSchedule"
                         }
                     }
                 ],
                 "responses": {
                     "201": {
-                        "description": "Task created successfully",
+                        "description": "Created schedule",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/task-pool_internal_domain_entity.Schedule"
                         }
                     },
                     "400": {
@@ -103,9 +120,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/tasks/{id}": {
+        "/api/v1/schedules/{id}": {
             "get": {
-                "description": "Get a specific task by its unique identifier",
+                "description": "Get a specific schedule by its unique identifier",
                 "consumes": [
                     "application/json"
                 ],
@@ -113,14 +130,14 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "tasks"
+                    "schedules"
                 ],
-                "summary": "Get task by ID",
+                "summary": "Get schedule by ID",
                 "parameters": [
                     {
                         "type": "integer",
                         "format": "int64",
-                        "description": "Task ID",
+                        "description": "Schedule ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -128,9 +145,9 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "Task details",
+                        "description": "Schedule details",
                         "schema": {
-                            "$ref": "#/definitions/task-pool_internal_domain_entity.Task"
+                            "$ref": "#/definitions/task-pool_internal_domain_entity.Schedule"
                         }
                     },
                     "400": {
@@ -143,7 +160,7 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "Task not found",
+                        "description": "Schedule not found",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -161,62 +178,1637 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        }
-    },
-    "definitions": {
-        "task-pool_internal_domain_entity.Task": {
-            "type": "object",
-            "properties": {
-                "createdAt": {
-                    "type": "string"
-                },
-                "description": {
-                    "type": "string"
-                },
-                "id": {
-                    "type": "integer"
-                },
-                "status": {
-                    "$ref": "#/definitions/task-pool_internal_domain_entity.TaskStatus"
-                },
-                "title": {
-                    "type": "string"
-                },
-                "updatedAt": {
-                    "type": "string"
+            },
+            "put": {
+                "description": "Replace the cron expression, task template and enabled state of a schedule",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schedules"
+                ],
+                "summary": "Update a schedule",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "Schedule ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Schedule update request",
+                        "name": "schedule",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/task-pool_internal_service_contracts.UpdateSchedule"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Updated schedule",
+                        "schema": {
+                            "$ref": "#/definitions/task-pool_internal_domain_entity.Schedule"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid input",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Schedule not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete a schedule, tasks it already created are kept",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schedules"
+                ],
+                "summary": "Delete a schedule",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "Schedule ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Schedule deleted successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid ID format",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Schedule not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
                 }
             }
         },
-        "task-pool_internal_domain_entity.TaskStatus": {
-            "type": "string",
-            "enum": [
-                "pending",
-                "completed",
-                "failed"
-            ],
-            "x-enum-varnames": [
-                "TaskStatusPending",
-                "TaskStatusCompleted",
-                "TaskStatusFailed"
-            ]
-        },
-        "task-pool_internal_service_contracts.CreateTask": {
-            "type": "object",
-            "required": [
-                "description",
-                "title"
-            ],
-            "properties": {
-                "description": {
-                    "type": "string",
-                    "maxLength": 255,
-                    "minLength": 3
-                },
-                "title": {
-                    "type": "string",
-                    "maxLength": 255,
-                    "minLength": 3
+        "/api/v1/tasks": {
+            "get": {
+                "description": "Get a page of tasks, optionally filtered and sorted. Pass next_cursor of a page as cursor to get the following one",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tasks"
+                ],
+                "summary": "Get all tasks",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Filter by status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by type",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by queue",
+                        "name": "queue",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created at or after, RFC 3339",
+                        "name": "created_after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created before, RFC 3339",
+                        "name": "created_before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "-created_at",
+                        "description": "id, created_at, updated_at or priority, prefix with - for descending order",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size, at most 100",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Page of tasks",
+                        "schema": {
+                            "$ref": "#/definitions/task-pool_internal_service_contracts.TaskPage"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid query",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create a new task and return it, the Location header points to the task. A request repeated with the same idempotency key returns the original task instead of creating another one",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tasks"
+                ],
+                "summary": "Create a new task",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Idempotency key, overrides idempotency_key of the body",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Task creation request",
+                        "name": "task",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/task-pool_internal_service_contracts.CreateTask"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Original task of a repeated request",
+                        "schema": {
+                            "$ref": "#/definitions/task-pool_internal_domain_entity.Task"
+                        },
+                        "headers": {
+                            "Location": {
+                                "type": "string",
+                                "description": "URL of the task"
+                            }
+                        }
+                    },
+                    "201": {
+                        "description": "Created task",
+                        "schema": {
+                            "$ref": "#/definitions/task-pool_internal_domain_entity.Task"
+                        },
+                        "headers": {
+                            "Location": {
+                                "type": "string",
+                                "description": "URL of the task"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid input",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Queue is full, retry after the Retry-After header",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Queue stayed full for the overflow timeout",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/tasks/batch": {
+            "post": {
+                "description": "Create up to 1000 tasks in one transaction and return their IDs in request order. When any task is invalid nothing is created and the offending tasks are reported by index",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tasks"
+                ],
+                "summary": "Create tasks in bulk",
+                "parameters": [
+                    {
+                        "description": "Tasks to create",
+                        "name": "tasks",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/task-pool_internal_service_contracts.CreateTasks"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "IDs of the created tasks",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "array",
+                                "items": {
+                                    "type": "integer",
+                                    "format": "int64"
+                                }
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid input",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Queue is full, retry after the Retry-After header",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Queue stayed full for the overflow timeout",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/tasks/dead": {
+            "get": {
+                "description": "Get the tasks the worker gave up on after exhausting their retries",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tasks"
+                ],
+                "summary": "Get dead-lettered tasks",
+                "responses": {
+                    "200": {
+                        "description": "List of dead-lettered tasks",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/task-pool_internal_domain_entity.Task"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete every dead-lettered task",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tasks"
+                ],
+                "summary": "Purge dead-lettered tasks",
+                "responses": {
+                    "200": {
+                        "description": "Number of purged tasks",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "integer",
+                                "format": "int64"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/tasks/dead/requeue": {
+            "post": {
+                "description": "Move the given dead-lettered tasks, or all of them when no ids are sent, back to pending",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tasks"
+                ],
+                "summary": "Requeue dead-lettered tasks",
+                "parameters": [
+                    {
+                        "description": "Tasks to requeue",
+                        "name": "tasks",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/task-pool_internal_service_contracts.RequeueTasks"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Number of requeued tasks",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "integer",
+                                "format": "int64"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid input",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/tasks/{id}": {
+            "get": {
+                "description": "Get a specific task by its unique identifier",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tasks"
+                ],
+                "summary": "Get task by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Task details",
+                        "schema": {
+                            "$ref": "#/definitions/task-pool_internal_domain_entity.Task"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid ID format",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Task not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/tasks/{id}/attempts": {
+            "get": {
+                "description": "Get every execution of a task with its worker, timing, outcome and error, oldest first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tasks"
+                ],
+                "summary": "Get task attempts",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "List of attempts",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/task-pool_internal_domain_entity.TaskAttempt"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid ID format",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Task not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/tasks/{id}/cancel": {
+            "post": {
+                "description": "Cancel a pending task so it never starts, or abort a running one through its handler context",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tasks"
+                ],
+                "summary": "Cancel a task",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Task cancelled successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - task already finished",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Task not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/tasks/{id}/events": {
+            "get": {
+                "description": "Stream the status, progress and result changes of a task as server-sent events. The current state of the task is sent first as a status event",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "events"
+                ],
+                "summary": "Stream task events",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Stream of task events",
+                        "schema": {
+                            "$ref": "#/definitions/task-pool_internal_event.TaskEvent"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid ID format",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Task not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/tasks/{id}/graph": {
+            "get": {
+                "description": "Get every task connected to the task through dependencies, with an edge from each dependency to the task waiting for it",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tasks"
+                ],
+                "summary": "Get task dependency graph",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Dependency graph",
+                        "schema": {
+                            "$ref": "#/definitions/task-pool_internal_service_contracts.TaskGraph"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid ID format",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Task not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/tasks/{id}/requeue": {
+            "post": {
+                "description": "Move a dead-lettered task back to pending with its attempts reset",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tasks"
+                ],
+                "summary": "Requeue a dead-lettered task",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Task requeued successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - task is not dead-lettered",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Task not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/workflows": {
+            "post": {
+                "description": "Create a workflow whose steps run one after another, the tasks of a step run in parallel and receive the results of the previous step as input. The Location header points to the workflow",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "workflows"
+                ],
+                "summary": "Create a new workflow",
+                "parameters": [
+                    {
+                        "description": "Workflow creation request",
+                        "name": "workflow",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/task-pool_internal_service_contracts.CreateWorkflow"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created workflow",
+                        "schema": {
+                            "$ref": "#/definitions/task-pool_internal_service_contracts.Workflow"
+                        },
+                        "headers": {
+                            "Location": {
+                                "type": "string",
+                                "description": "URL of the workflow"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid input",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Queue is full, retry after the Retry-After header",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Queue stayed full for the overflow timeout",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/workflows/{id}": {
+            "get": {
+                "description": "Get a workflow with its tasks grouped by step, its status and progress are derived from the tasks",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "workflows"
+                ],
+                "summary": "Get workflow by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "Workflow ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Workflow details",
+                        "schema": {
+                            "$ref": "#/definitions/task-pool_internal_service_contracts.Workflow"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid ID format",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Workflow not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/ws": {
+            "get": {
+                "description": "Upgrade to a WebSocket connection. The client sends {\"action\": \"subscribe\" | \"unsubscribe\" | \"ping\", \"task_ids\": [], \"queues\": [], \"statuses\": []} messages and receives the matching task events as JSON frames",
+                "tags": [
+                    "events"
+                ],
+                "summary": "Subscribe to task events over WebSocket",
+                "responses": {
+                    "101": {
+                        "description": "Switching protocols, frames are task events",
+                        "schema": {
+                            "$ref": "#/definitions/task-pool_internal_event.TaskEvent"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - not a WebSocket upgrade",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "task-pool_internal_domain_entity.Schedule": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "cron": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "enabled": {
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "lastRunAt": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "nextRunAt": {
+                    "type": "string"
+                },
+                "payload": {
+                    "type": "object"
+                },
+                "title": {
+                    "description": "Template of the tasks created by the schedule",
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "task-pool_internal_domain_entity.Task": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "description": "Attempts counts the executions started so far. MaxAttempts overrides\nthe worker retry policy when greater than zero.",
+                    "type": "integer"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "deadLetteredAt": {
+                    "description": "DeadLetteredAt is set when the worker gives up on the task.",
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "heartbeatAt": {
+                    "description": "HeartbeatAt is refreshed by the worker holding the task while it is\nqueued or running, a stale heartbeat means that worker is gone.",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "idempotencyKey": {
+                    "description": "IdempotencyKey identifies the create request of the task, a request\nrepeated with the same key returns this task instead of a new one.",
+                    "type": "string"
+                },
+                "input": {
+                    "description": "Input holds the results of the dependencies once they have completed:\nthe result of the only dependency, or an array of the results ordered\nby dependency ID.",
+                    "type": "object"
+                },
+                "maxAttempts": {
+                    "type": "integer"
+                },
+                "nextRunAt": {
+                    "type": "string"
+                },
+                "payload": {
+                    "type": "object"
+                },
+                "priority": {
+                    "description": "Priority orders due tasks, higher values are claimed first. Claims are\nserved by idx_tasks_claim on queue, status, priority and ID.",
+                    "type": "integer"
+                },
+                "progress": {
+                    "description": "Progress is the percentage of the current attempt reported done by the\nhandler, ProgressMessage describes the step it is at.",
+                    "type": "integer"
+                },
+                "progressMessage": {
+                    "type": "string"
+                },
+                "queue": {
+                    "type": "string"
+                },
+                "result": {
+                    "description": "Result holds the JSON value returned by the handler of a completed task.",
+                    "type": "object"
+                },
+                "scheduleID": {
+                    "description": "ScheduleID references the schedule that created the task, if any.",
+                    "type": "integer"
+                },
+                "startedAt": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/task-pool_internal_domain_entity.TaskStatus"
+                },
+                "timeout": {
+                    "description": "Timeout overrides the worker execution timeout when greater than zero.",
+                    "type": "string",
+                    "example": "30s"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
+                },
+                "workflowID": {
+                    "description": "WorkflowID references the workflow the task is a step of, if any.\nWorkflowStep is the position of that step, starting at 0.",
+                    "type": "integer"
+                },
+                "workflowStep": {
+                    "type": "integer"
+                }
+            }
+        },
+        "task-pool_internal_domain_entity.TaskAttempt": {
+            "type": "object",
+            "properties": {
+                "attempt": {
+                    "type": "integer"
+                },
+                "duration": {
+                    "type": "integer"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "finishedAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "outcome": {
+                    "description": "Outcome is the status the task moved to after the attempt, running\nwhile the attempt is in progress.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/task-pool_internal_domain_entity.TaskStatus"
+                        }
+                    ]
+                },
+                "startedAt": {
+                    "type": "string"
+                },
+                "taskID": {
+                    "type": "integer"
+                },
+                "workerID": {
+                    "type": "string"
+                }
+            }
+        },
+        "task-pool_internal_domain_entity.TaskStatus": {
+            "type": "string",
+            "enum": [
+                "blocked",
+                "pending",
+                "queued",
+                "running",
+                "retrying",
+                "completed",
+                "failed",
+                "timed_out",
+                "cancelled"
+            ],
+            "x-enum-varnames": [
+                "TaskStatusBlocked",
+                "TaskStatusPending",
+                "TaskStatusQueued",
+                "TaskStatusRunning",
+                "TaskStatusRetrying",
+                "TaskStatusCompleted",
+                "TaskStatusFailed",
+                "TaskStatusTimedOut",
+                "TaskStatusCancelled"
+            ]
+        },
+        "task-pool_internal_domain_entity.WorkflowStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "running",
+                "completed",
+                "failed",
+                "cancelled"
+            ],
+            "x-enum-varnames": [
+                "WorkflowStatusPending",
+                "WorkflowStatusRunning",
+                "WorkflowStatusCompleted",
+                "WorkflowStatusFailed",
+                "WorkflowStatusCancelled"
+            ]
+        },
+        "task-pool_internal_event.TaskEvent": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "progress": {
+                    "type": "integer"
+                },
+                "progress_message": {
+                    "type": "string"
+                },
+                "queue": {
+                    "type": "string"
+                },
+                "result": {
+                    "type": "object"
+                },
+                "status": {
+                    "$ref": "#/definitions/task-pool_internal_domain_entity.TaskStatus"
+                },
+                "task_id": {
+                    "type": "integer"
+                },
+                "time": {
+                    "type": "string"
+                },
+                "type": {
+                    "$ref": "#/definitions/task-pool_internal_event.Type"
+                }
+            }
+        },
+        "task-pool_internal_event.Type": {
+            "type": "string",
+            "enum": [
+                "status",
+                "progress"
+            ],
+            "x-enum-varnames": [
+                "TypeStatus",
+                "TypeProgress"
+            ]
+        },
+        "task-pool_internal_service_contracts.CreateSchedule": {
+            "type": "object",
+            "required": [
+                "cron",
+                "description",
+                "name",
+                "title",
+                "type"
+            ],
+            "properties": {
+                "cron": {
+                    "description": "Cron is a standard five field cron expression or a descriptor such as\n\"@hourly\".",
+                    "type": "string",
+                    "example": "*/5 * * * *"
+                },
+                "description": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "minLength": 3
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "minLength": 3
+                },
+                "payload": {
+                    "type": "object"
+                },
+                "title": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "minLength": 3
+                },
+                "type": {
+                    "type": "string",
+                    "maxLength": 255
+                }
+            }
+        },
+        "task-pool_internal_service_contracts.CreateTask": {
+            "type": "object",
+            "required": [
+                "description",
+                "title",
+                "type"
+            ],
+            "properties": {
+                "delay": {
+                    "type": "string",
+                    "example": "5m"
+                },
+                "depends_on": {
+                    "description": "DependsOn lists the tasks that must complete before this one runs, the\ntask is blocked until then and cancelled if one of them does not\ncomplete.",
+                    "type": "array",
+                    "maxItems": 100,
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        1,
+                        2
+                    ]
+                },
+                "description": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "minLength": 3
+                },
+                "idempotency_key": {
+                    "description": "IdempotencyKey makes retries of the request safe, the Idempotency-Key\nheader takes precedence over it.",
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "order-1234-receipt"
+                },
+                "max_attempts": {
+                    "description": "MaxAttempts overrides TASK_WORKER_MAX_ATTEMPTS for this task when set.",
+                    "type": "integer",
+                    "maximum": 100,
+                    "minimum": 1
+                },
+                "payload": {
+                    "type": "object"
+                },
+                "priority": {
+                    "description": "Priority ranges from 0 to 10, higher priorities are processed first.",
+                    "type": "integer",
+                    "maximum": 10,
+                    "minimum": 0
+                },
+                "queue": {
+                    "description": "Queue selects the worker pool of the task, \"default\" when empty.",
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "emails"
+                },
+                "run_at": {
+                    "description": "RunAt and Delay postpone the first run of the task, at most one of\nthem may be set. Delay is a duration such as \"90s\" or \"1h30m\".",
+                    "type": "string"
+                },
+                "timeout": {
+                    "description": "Timeout overrides TASK_WORKER_TIMEOUT for this task, e.g. \"30s\".",
+                    "type": "string",
+                    "example": "30s"
+                },
+                "title": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "minLength": 3
+                },
+                "type": {
+                    "type": "string",
+                    "maxLength": 255
+                }
+            }
+        },
+        "task-pool_internal_service_contracts.CreateTasks": {
+            "type": "object",
+            "required": [
+                "tasks"
+            ],
+            "properties": {
+                "tasks": {
+                    "description": "Tasks holds at most 1000 tasks, idempotency keys and dependencies are\nnot supported.",
+                    "type": "array",
+                    "maxItems": 1000,
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/task-pool_internal_service_contracts.CreateTask"
+                    }
+                }
+            }
+        },
+        "task-pool_internal_service_contracts.CreateWorkflow": {
+            "type": "object",
+            "required": [
+                "name",
+                "steps"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "minLength": 3
+                },
+                "steps": {
+                    "description": "Steps run one after another, a step starts once every task of the\nprevious step has completed. Idempotency keys and dependencies of the\ntasks are not supported.",
+                    "type": "array",
+                    "maxItems": 100,
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/task-pool_internal_service_contracts.WorkflowStep"
+                    }
+                }
+            }
+        },
+        "task-pool_internal_service_contracts.RequeueTasks": {
+            "type": "object",
+            "properties": {
+                "ids": {
+                    "description": "IDs limits the requeue to the given tasks, all dead-lettered tasks are\nrequeued when empty.",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "task-pool_internal_service_contracts.TaskGraph": {
+            "type": "object",
+            "properties": {
+                "edges": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/task-pool_internal_service_contracts.TaskGraphEdge"
+                    }
+                },
+                "nodes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/task-pool_internal_service_contracts.TaskGraphNode"
+                    }
+                }
+            }
+        },
+        "task-pool_internal_service_contracts.TaskGraphEdge": {
+            "type": "object",
+            "properties": {
+                "from": {
+                    "type": "integer"
+                },
+                "to": {
+                    "type": "integer"
+                }
+            }
+        },
+        "task-pool_internal_service_contracts.TaskGraphNode": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "queue": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/task-pool_internal_domain_entity.TaskStatus"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "task-pool_internal_service_contracts.TaskPage": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/task-pool_internal_domain_entity.Task"
+                    }
+                },
+                "next_cursor": {
+                    "description": "NextCursor fetches the following page, it is empty on the last page.",
+                    "type": "string"
+                },
+                "total": {
+                    "description": "Total counts the tasks matching the query across all pages.",
+                    "type": "integer"
+                }
+            }
+        },
+        "task-pool_internal_service_contracts.UpdateSchedule": {
+            "type": "object",
+            "required": [
+                "cron",
+                "description",
+                "name",
+                "title",
+                "type"
+            ],
+            "properties": {
+                "cron": {
+                    "description": "Cron is a standard five field cron expression or a descriptor such as\n\"@hourly\".",
+                    "type": "string",
+                    "example": "*/5 * * * *"
+                },
+                "description": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "minLength": 3
+                },
+                "enabled": {
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "minLength": 3
+                },
+                "payload": {
+                    "type": "object"
+                },
+                "title": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "minLength": 3
+                },
+                "type": {
+                    "type": "string",
+                    "maxLength": 255
+                }
+            }
+        },
+        "task-pool_internal_service_contracts.Workflow": {
+            "type": "object",
+            "properties": {
+                "completed": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "progress": {
+                    "description": "Progress is the percentage of completed tasks.",
+                    "type": "integer",
+                    "example": 50
+                },
+                "status": {
+                    "$ref": "#/definitions/task-pool_internal_domain_entity.WorkflowStatus"
+                },
+                "steps": {
+                    "type": "array",
+                    "items": {
+                        "type": "array",
+                        "items": {
+                            "$ref": "#/definitions/task-pool_internal_domain_entity.Task"
+                        }
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "task-pool_internal_service_contracts.WorkflowStep": {
+            "type": "object",
+            "required": [
+                "tasks"
+            ],
+            "properties": {
+                "tasks": {
+                    "type": "array",
+                    "maxItems": 100,
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/task-pool_internal_service_contracts.CreateTask"
+                    }
                 }
             }
         }
@@ -0,0 +1,152 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"task-pool/internal/domain/entity"
+	"task-pool/internal/domain/repository"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type scheduleRepository struct {
+	db *gorm.DB
+}
+
+func NewScheduleRepository(db *gorm.DB) repository.ScheduleRepository {
+	return &scheduleRepository{db: db}
+}
+
+func (r *scheduleRepository) model(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx).Model(&entity.Schedule{})
+}
+
+func (r *scheduleRepository) Create(ctx context.Context, schedule *entity.Schedule) error {
+	err := r.model(ctx).Create(schedule).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return repository.ErrScheduleAlreadyExists
+		}
+
+		return fmt.Errorf("failed to create schedule: %w", err)
+	}
+
+	return nil
+}
+
+func (r *scheduleRepository) FindByID(ctx context.Context, id uint64) (*entity.Schedule, error) {
+	var schedule entity.Schedule
+
+	err := r.model(ctx).Where("id = ?", id).First(&schedule).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, repository.ErrScheduleNotFound
+		}
+
+		return nil, fmt.Errorf("failed to get schedule: %w", err)
+	}
+
+	return &schedule, nil
+}
+
+func (r *scheduleRepository) FindAll(ctx context.Context) ([]*entity.Schedule, error) {
+	var schedules []*entity.Schedule
+
+	err := r.model(ctx).Order("id").Find(&schedules).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get schedules: %w", err)
+	}
+
+	return schedules, nil
+}
+
+func (r *scheduleRepository) Update(ctx context.Context, schedule *entity.Schedule) error {
+	result := r.update(r.db.WithContext(ctx), schedule)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrDuplicatedKey) {
+			return repository.ErrScheduleAlreadyExists
+		}
+
+		return fmt.Errorf("failed to update schedule: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return repository.ErrScheduleNotFound
+	}
+
+	return nil
+}
+
+func (r *scheduleRepository) update(db *gorm.DB, schedule *entity.Schedule) *gorm.DB {
+	return db.Model(&entity.Schedule{}).Where("id = ?", schedule.ID).Updates(map[string]interface{}{
+		"name":        schedule.Name,
+		"cron":        schedule.Cron,
+		"enabled":     schedule.Enabled,
+		"title":       schedule.Title,
+		"description": schedule.Description,
+		"type":        schedule.Type,
+		"payload":     schedule.Payload,
+		"next_run_at": schedule.NextRunAt,
+		"last_run_at": schedule.LastRunAt,
+	})
+}
+
+func (r *scheduleRepository) Delete(ctx context.Context, id uint64) error {
+	result := r.db.WithContext(ctx).Where("id = ?", id).Delete(&entity.Schedule{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete schedule: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return repository.ErrScheduleNotFound
+	}
+
+	return nil
+}
+
+func (r *scheduleRepository) FireDue(
+	ctx context.Context,
+	now time.Time,
+	fire func(schedule *entity.Schedule) *entity.Task,
+) ([]*entity.Task, error) {
+	var tasks []*entity.Task
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var schedules []*entity.Schedule
+
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("enabled AND next_run_at <= ?", now).
+			Order("next_run_at").
+			Find(&schedules).Error
+		if err != nil {
+			return fmt.Errorf("failed to get due schedules: %w", err)
+		}
+
+		for _, schedule := range schedules {
+			task := fire(schedule)
+			if task != nil {
+				err = tx.Create(task).Error
+				if err != nil {
+					return fmt.Errorf("failed to create task for schedule %d: %w", schedule.ID, err)
+				}
+
+				tasks = append(tasks, task)
+			}
+
+			err = r.update(tx, schedule).Error
+			if err != nil {
+				return fmt.Errorf("failed to update schedule %d: %w", schedule.ID, err)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return tasks, nil
+}
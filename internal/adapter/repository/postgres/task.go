@@ -2,12 +2,16 @@ package postgres
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
+	"slices"
 	"task-pool/internal/domain/entity"
 	"task-pool/internal/domain/repository"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type taskRepository struct {
@@ -23,14 +27,56 @@ func (r *taskRepository) model(ctx context.Context) *gorm.DB {
 }
 
 func (r *taskRepository) Create(ctx context.Context, task *entity.Task) error {
-	err := r.model(ctx).Create(task).Error
+	return insertTask(r.model(ctx), task)
+}
+
+// insertTask inserts the task unless its idempotency key is taken, in which
+// case it returns ErrTaskExists.
+func insertTask(db *gorm.DB, task *entity.Task) error {
+	result := db.
+		Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "idempotency_key"}}, DoNothing: true}).
+		Create(task)
+	if result.Error != nil {
+		return fmt.Errorf("failed to create task: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return repository.ErrTaskExists
+	}
+
+	return nil
+}
+
+// createBatchSize keeps every INSERT of CreateBatch well below the limit of
+// 65535 bind parameters.
+const createBatchSize = 500
+
+func (r *taskRepository) CreateBatch(ctx context.Context, tasks []*entity.Task) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return tx.CreateInBatches(tasks, createBatchSize).Error
+	})
 	if err != nil {
-		return fmt.Errorf("failed to create task: %w", err)
+		return fmt.Errorf("failed to create tasks: %w", err)
 	}
 
 	return nil
 }
 
+func (r *taskRepository) FindByIdempotencyKey(ctx context.Context, key string) (*entity.Task, error) {
+	var task entity.Task
+
+	err := r.model(ctx).Where("idempotency_key = ?", key).First(&task).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, repository.ErrTaskNotFound
+		}
+
+		return nil, fmt.Errorf("failed to get task: %w", err)
+	}
+
+	return &task, nil
+}
+
 func (r *taskRepository) FindByID(ctx context.Context, id uint64) (*entity.Task, error) {
 	var task entity.Task
 
@@ -46,26 +92,316 @@ func (r *taskRepository) FindByID(ctx context.Context, id uint64) (*entity.Task,
 	return &task, nil
 }
 
-func (r *taskRepository) FindAll(ctx context.Context) ([]*entity.Task, error) {
-	var tasks []*entity.Task
+func (r *taskRepository) FindAll(ctx context.Context, filter repository.TaskFilter) (*repository.TaskPage, error) {
+	if !slices.Contains(repository.TaskSorts, filter.Sort) {
+		return nil, fmt.Errorf("unknown sort %q", filter.Sort)
+	}
 
-	err := r.model(ctx).Find(&tasks).Error
+	query := r.model(ctx)
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.Type != "" {
+		query = query.Where("type = ?", filter.Type)
+	}
+	if filter.Queue != "" {
+		query = query.Where("queue = ?", filter.Queue)
+	}
+	if filter.CreatedAfter != nil {
+		query = query.Where("created_at >= ?", *filter.CreatedAfter)
+	}
+	if filter.CreatedBefore != nil {
+		query = query.Where("created_at < ?", *filter.CreatedBefore)
+	}
+	query = query.Session(&gorm.Session{})
+
+	var total int64
+	err := query.Count(&total).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to count tasks: %w", err)
+	}
+
+	direction, op := "ASC", ">"
+	if filter.Desc {
+		direction, op = "DESC", "<"
+	}
+
+	page := query
+	if filter.Cursor != "" {
+		cursor, err := decodeTaskCursor(filter.Cursor, filter.Sort, filter.Desc)
+		if err != nil {
+			return nil, err
+		}
+
+		if filter.Sort == repository.TaskSortID {
+			page = page.Where("id "+op+" ?", cursor.ID)
+		} else {
+			page = page.Where("("+filter.Sort+", id) "+op+" (?, ?)", cursor.value, cursor.ID)
+		}
+	}
+
+	order := "id " + direction
+	if filter.Sort != repository.TaskSortID {
+		order = filter.Sort + " " + direction + ", " + order
+	}
+
+	// One extra row tells whether there is a next page.
+	tasks := make([]*entity.Task, 0, filter.Limit+1)
+	err = page.Order(order).Limit(filter.Limit + 1).Find(&tasks).Error
 	if err != nil {
 		return nil, fmt.Errorf("failed to get tasks: %w", err)
 	}
 
-	return tasks, nil
+	result := &repository.TaskPage{Tasks: tasks, Total: total}
+	if len(tasks) > filter.Limit {
+		result.Tasks = tasks[:filter.Limit]
+		result.NextCursor, err = encodeTaskCursor(result.Tasks[filter.Limit-1], filter.Sort, filter.Desc)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
 }
 
-func (r *taskRepository) Update(ctx context.Context, task *entity.Task) error {
-	err := r.model(ctx).Where("id = ?", task.ID).Updates(map[string]interface{}{
-		"title":       task.Title,
-		"description": task.Description,
-		"status":      task.Status,
-	}).Error
+func (r *taskRepository) Update(ctx context.Context, task *entity.Task, attempt int) error {
+	result := r.model(ctx).
+		Where("id = ? AND status IN ? AND attempts = ?", task.ID, heldStatuses, attempt).
+		Updates(map[string]interface{}{
+			"title":            task.Title,
+			"description":      task.Description,
+			"type":             task.Type,
+			"queue":            task.Queue,
+			"payload":          task.Payload,
+			"priority":         task.Priority,
+			"status":           task.Status,
+			"error":            task.Error,
+			"result":           task.Result,
+			"progress":         task.Progress,
+			"progress_message": task.ProgressMessage,
+			"attempts":         task.Attempts,
+			"max_attempts":     task.MaxAttempts,
+			"timeout":          task.Timeout,
+			"next_run_at":      task.NextRunAt,
+			"started_at":       task.StartedAt,
+			"dead_lettered_at": task.DeadLetteredAt,
+		})
+	if result.Error != nil {
+		return fmt.Errorf("failed to update task: %w", result.Error)
+	}
+
+	if result.RowsAffected > 0 {
+		return nil
+	}
+
+	// Nothing matched, tell why the worker no longer holds the task.
+	var statuses []entity.TaskStatus
+	err := r.model(ctx).Where("id = ?", task.ID).Pluck("status", &statuses).Error
 	if err != nil {
 		return fmt.Errorf("failed to update task: %w", err)
 	}
 
+	switch {
+	case len(statuses) == 0:
+		return repository.ErrTaskNotFound
+	case statuses[0] == entity.TaskStatusCancelled:
+		return repository.ErrTaskCancelled
+	default:
+		return repository.ErrTaskLost
+	}
+}
+
+func (r *taskRepository) UpdateProgress(ctx context.Context, id uint64, progress int, message string) error {
+	err := r.model(ctx).Where("id = ? AND status = ?", id, entity.TaskStatusRunning).Updates(map[string]interface{}{
+		"progress":         progress,
+		"progress_message": message,
+	}).Error
+	if err != nil {
+		return fmt.Errorf("failed to update task progress: %w", err)
+	}
+
 	return nil
 }
+
+func (r *taskRepository) ClaimNext(ctx context.Context, queue string, aging time.Duration) (*entity.Task, error) {
+	var tasks []*entity.Task
+
+	now := time.Now()
+
+	due := func() *gorm.DB {
+		return r.db.Model(&entity.Task{}).
+			Select("id").
+			Where("status IN ? AND queue = ?", entity.TransitionsTo(entity.TaskStatusQueued), queue).
+			Where("next_run_at IS NULL OR next_run_at <= ?", now)
+	}
+
+	next := due()
+	if aging > 0 {
+		// Aging is computed over a bounded set of candidates instead of
+		// sorting the whole backlog: the highest priority tasks, served by
+		// idx_tasks_claim, and the oldest ones, the only ones aging raises.
+		candidates := r.db.Raw("(?) UNION (?)",
+			due().Order("priority DESC, id").Limit(claimCandidates),
+			due().Order("id").Limit(claimCandidates),
+		)
+		next = next.Where("id IN (?)", candidates)
+	}
+
+	next = next.
+		Order(byEffectivePriority(now, aging)).
+		Limit(1).
+		Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"})
+
+	err := r.db.WithContext(ctx).Model(&tasks).
+		Clauses(clause.Returning{}).
+		Where("id IN (?)", next).
+		Updates(map[string]interface{}{
+			"status":       entity.TaskStatusQueued,
+			"attempts":     gorm.Expr("attempts + 1"),
+			"heartbeat_at": now,
+		}).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim task: %w", err)
+	}
+
+	if len(tasks) == 0 {
+		return nil, repository.ErrNoTaskAvailable
+	}
+
+	return tasks[0], nil
+}
+
+// claimCandidates bounds each set of tasks ClaimNext picks from when aging is
+// enabled. It only has to exceed the number of concurrent claims of a queue,
+// the candidates locked by them are skipped.
+const claimCandidates = 100
+
+// byEffectivePriority orders tasks by their priority raised by one for every
+// aging interval they have been due, so low-priority work is not starved.
+// Ties are claimed in creation order.
+func byEffectivePriority(now time.Time, aging time.Duration) clause.OrderBy {
+	if aging <= 0 {
+		return clause.OrderBy{Expression: clause.Expr{SQL: "priority DESC, id"}}
+	}
+
+	return clause.OrderBy{Expression: clause.Expr{
+		SQL:  "priority + FLOOR(EXTRACT(EPOCH FROM (? - COALESCE(next_run_at, created_at))) / ?) DESC, id",
+		Vars: []interface{}{now, aging.Seconds()},
+	}}
+}
+
+func (r *taskRepository) Cancel(ctx context.Context, id uint64) (*entity.Task, error) {
+	var tasks []*entity.Task
+
+	err := r.db.WithContext(ctx).Model(&tasks).
+		Clauses(clause.Returning{}).
+		Where("id = ? AND status IN ?", id, entity.TransitionsTo(entity.TaskStatusCancelled)).
+		Updates(map[string]interface{}{
+			"status":      entity.TaskStatusCancelled,
+			"next_run_at": nil,
+		}).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to cancel task: %w", err)
+	}
+
+	if len(tasks) > 0 {
+		return tasks[0], nil
+	}
+
+	// Nothing was updated, tell a missing task from a finished one.
+	_, err = r.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, repository.ErrTaskNotCancellable
+}
+
+func (r *taskRepository) FindCancelled(ctx context.Context, ids []uint64) ([]uint64, error) {
+	var cancelled []uint64
+
+	err := r.model(ctx).
+		Where("id IN ? AND status = ?", ids, entity.TaskStatusCancelled).
+		Pluck("id", &cancelled).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cancelled tasks: %w", err)
+	}
+
+	return cancelled, nil
+}
+
+func (r *taskRepository) CountPending(ctx context.Context, queue string) (int64, error) {
+	var count int64
+
+	err := r.model(ctx).
+		Where("status IN ? AND queue = ?", entity.TransitionsTo(entity.TaskStatusQueued), queue).
+		Where("next_run_at IS NULL OR next_run_at <= ?", time.Now()).
+		Count(&count).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to count pending tasks: %w", err)
+	}
+
+	return count, nil
+}
+
+func (r *taskRepository) FindNextRunAt(ctx context.Context) (*time.Time, error) {
+	var nextRunAt sql.NullTime
+
+	err := r.model(ctx).
+		Select("MIN(next_run_at)").
+		Where("status IN ? AND next_run_at > ?", entity.TransitionsTo(entity.TaskStatusQueued), time.Now()).
+		Row().
+		Scan(&nextRunAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get next run time: %w", err)
+	}
+
+	if !nextRunAt.Valid {
+		return nil, nil
+	}
+
+	return &nextRunAt.Time, nil
+}
+
+func (r *taskRepository) FindDeadLettered(ctx context.Context) ([]*entity.Task, error) {
+	var tasks []*entity.Task
+
+	err := r.model(ctx).Where("dead_lettered_at IS NOT NULL").Order("dead_lettered_at").Find(&tasks).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dead-lettered tasks: %w", err)
+	}
+
+	return tasks, nil
+}
+
+func (r *taskRepository) RequeueDeadLettered(ctx context.Context, ids []uint64) ([]*entity.Task, error) {
+	var tasks []*entity.Task
+
+	query := r.db.WithContext(ctx).Model(&tasks).
+		Clauses(clause.Returning{}).
+		Where("dead_lettered_at IS NOT NULL")
+	if len(ids) > 0 {
+		query = query.Where("id IN ?", ids)
+	}
+
+	err := query.Updates(map[string]interface{}{
+		"status":           entity.TaskStatusPending,
+		"attempts":         0,
+		"next_run_at":      nil,
+		"dead_lettered_at": nil,
+	}).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to requeue dead-lettered tasks: %w", err)
+	}
+
+	return tasks, nil
+}
+
+func (r *taskRepository) PurgeDeadLettered(ctx context.Context) (int64, error) {
+	result := r.db.WithContext(ctx).Where("dead_lettered_at IS NOT NULL").Delete(&entity.Task{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to purge dead-lettered tasks: %w", result.Error)
+	}
+
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,56 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"task-pool/internal/domain/entity"
+	"task-pool/internal/domain/repository"
+
+	"gorm.io/gorm"
+)
+
+type taskAttemptRepository struct {
+	db *gorm.DB
+}
+
+func NewTaskAttemptRepository(db *gorm.DB) repository.TaskAttemptRepository {
+	return &taskAttemptRepository{db: db}
+}
+
+func (r *taskAttemptRepository) model(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx).Model(&entity.TaskAttempt{})
+}
+
+func (r *taskAttemptRepository) Create(ctx context.Context, attempt *entity.TaskAttempt) error {
+	err := r.model(ctx).Create(attempt).Error
+	if err != nil {
+		return fmt.Errorf("failed to create task attempt: %w", err)
+	}
+
+	return nil
+}
+
+func (r *taskAttemptRepository) Update(ctx context.Context, attempt *entity.TaskAttempt) error {
+	err := r.model(ctx).Where("id = ?", attempt.ID).Updates(map[string]interface{}{
+		"finished_at": attempt.FinishedAt,
+		"duration":    attempt.Duration,
+		"outcome":     attempt.Outcome,
+		"error":       attempt.Error,
+	}).Error
+	if err != nil {
+		return fmt.Errorf("failed to update task attempt: %w", err)
+	}
+
+	return nil
+}
+
+func (r *taskAttemptRepository) FindByTaskID(ctx context.Context, taskID uint64) ([]*entity.TaskAttempt, error) {
+	var attempts []*entity.TaskAttempt
+
+	err := r.model(ctx).Where("task_id = ?", taskID).Order("id").Find(&attempts).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get task attempts: %w", err)
+	}
+
+	return attempts, nil
+}
@@ -0,0 +1,84 @@
+package postgres
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"task-pool/internal/domain/entity"
+	"task-pool/internal/domain/repository"
+	"time"
+)
+
+// taskCursor is the position of a task in a sorted listing. It carries the
+// sort it was created for so that it cannot be used with another one.
+type taskCursor struct {
+	Sort  string          `json:"s"`
+	Desc  bool            `json:"d,omitempty"`
+	Value json.RawMessage `json:"v,omitempty"`
+	ID    uint64          `json:"id"`
+
+	// value is Value decoded into the type of the sort column.
+	value any
+}
+
+func encodeTaskCursor(task *entity.Task, sort string, desc bool) (string, error) {
+	cursor := taskCursor{Sort: sort, Desc: desc, ID: task.ID}
+
+	var value any
+	switch sort {
+	case repository.TaskSortCreatedAt:
+		value = task.CreatedAt
+	case repository.TaskSortUpdatedAt:
+		value = task.UpdatedAt
+	case repository.TaskSortPriority:
+		value = task.Priority
+	}
+
+	if value != nil {
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return "", fmt.Errorf("failed to encode cursor: %w", err)
+		}
+		cursor.Value = raw
+	}
+
+	data, err := json.Marshal(cursor)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode cursor: %w", err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func decodeTaskCursor(encoded, sort string, desc bool) (*taskCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", repository.ErrInvalidCursor, err)
+	}
+
+	var cursor taskCursor
+	err = json.Unmarshal(data, &cursor)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", repository.ErrInvalidCursor, err)
+	}
+
+	if cursor.Sort != sort || cursor.Desc != desc {
+		return nil, fmt.Errorf("%w: cursor belongs to another sort", repository.ErrInvalidCursor)
+	}
+
+	switch sort {
+	case repository.TaskSortCreatedAt, repository.TaskSortUpdatedAt:
+		var value time.Time
+		err = json.Unmarshal(cursor.Value, &value)
+		cursor.value = value
+	case repository.TaskSortPriority:
+		var value int
+		err = json.Unmarshal(cursor.Value, &value)
+		cursor.value = value
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", repository.ErrInvalidCursor, err)
+	}
+
+	return &cursor, nil
+}
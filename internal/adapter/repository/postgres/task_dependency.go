@@ -0,0 +1,236 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"task-pool/internal/domain/entity"
+	"task-pool/internal/domain/repository"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func (r *taskRepository) CreateWithDependencies(ctx context.Context, task *entity.Task, dependsOn []uint64) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return createWithDependencies(tx, task, dependsOn)
+	})
+}
+
+// createWithDependencies inserts the task and its dependencies within tx.
+// The dependencies are share locked, so none of them can finish before the
+// dependency rows are visible to ResolveDependents.
+func createWithDependencies(tx *gorm.DB, task *entity.Task, dependsOn []uint64) error {
+	var parents []*entity.Task
+
+	err := tx.Model(&entity.Task{}).
+		Clauses(clause.Locking{Strength: "SHARE"}).
+		Select("id", "status").
+		Where("id IN ?", dependsOn).
+		Find(&parents).Error
+	if err != nil {
+		return fmt.Errorf("failed to get dependencies: %w", err)
+	}
+
+	statuses := make(map[uint64]entity.TaskStatus, len(parents))
+	for _, parent := range parents {
+		statuses[parent.ID] = parent.Status
+	}
+
+	blocked := false
+	for _, id := range dependsOn {
+		status, ok := statuses[id]
+		switch {
+		case !ok:
+			return fmt.Errorf("%w: task %d", repository.ErrDependencyNotFound, id)
+		case status == entity.TaskStatusCompleted:
+		case status == entity.TaskStatusFailed, status == entity.TaskStatusTimedOut, status == entity.TaskStatusCancelled:
+			return fmt.Errorf("%w: task %d is %s", repository.ErrDependencyFailed, id, status)
+		default:
+			blocked = true
+		}
+	}
+
+	if blocked {
+		task.Status = entity.TaskStatusBlocked
+	}
+
+	err = insertTask(tx.Model(&entity.Task{}), task)
+	if err != nil {
+		return err
+	}
+
+	dependencies := make([]*entity.TaskDependency, 0, len(dependsOn))
+	for _, id := range dependsOn {
+		dependencies = append(dependencies, &entity.TaskDependency{TaskID: task.ID, DependsOnID: id})
+	}
+
+	err = tx.Create(&dependencies).Error
+	if err != nil {
+		return fmt.Errorf("failed to create dependencies: %w", err)
+	}
+
+	if blocked {
+		return nil
+	}
+
+	// The dependencies have completed already, their results are the input.
+	err = tx.Model(task).
+		Clauses(clause.Returning{Columns: []clause.Column{{Name: "input"}}}).
+		Update("input", gorm.Expr(dependencyInput)).Error
+	if err != nil {
+		return fmt.Errorf("failed to set task input: %w", err)
+	}
+
+	return nil
+}
+
+// dependencyInput is the input of a task whose dependencies have completed:
+// the result of its only dependency, or the results of all of them ordered by
+// ID.
+const dependencyInput = `(
+	SELECT CASE WHEN COUNT(*) = 1 THEN jsonb_agg(p.result) -> 0 ELSE jsonb_agg(p.result ORDER BY p.id) END
+	FROM task_dependencies d JOIN tasks p ON p.id = d.depends_on_id
+	WHERE d.task_id = tasks.id
+)`
+
+func (r *taskRepository) ResolveDependents(ctx context.Context, task *entity.Task) ([]*entity.Task, error) {
+	switch task.Status {
+	case entity.TaskStatusCompleted:
+		return r.releaseDependents(ctx, task.ID)
+	case entity.TaskStatusFailed, entity.TaskStatusTimedOut, entity.TaskStatusCancelled:
+		return r.cancelDependents(ctx, task.ID)
+	}
+
+	return nil, nil
+}
+
+// releaseDependents moves the blocked dependents of a completed task to
+// pending, with the results of their dependencies as input, once none of
+// their dependencies is left unfinished. Every
+// completing dependency runs this after its own update is committed, so the
+// last one to complete always sees the others as completed.
+func (r *taskRepository) releaseDependents(ctx context.Context, id uint64) ([]*entity.Task, error) {
+	return r.releaseBlocked(ctx, "id IN (SELECT task_id FROM task_dependencies WHERE depends_on_id = ?)", id)
+}
+
+// releaseBlocked moves the blocked tasks matching query whose dependencies
+// have all completed to pending, with their results as input.
+func (r *taskRepository) releaseBlocked(ctx context.Context, query string, args ...interface{}) ([]*entity.Task, error) {
+	var released []*entity.Task
+
+	err := r.db.WithContext(ctx).Model(&released).
+		Clauses(clause.Returning{}).
+		Where("status = ?", entity.TaskStatusBlocked).
+		Where(query, args...).
+		Where(`NOT EXISTS (
+			SELECT 1 FROM task_dependencies d JOIN tasks p ON p.id = d.depends_on_id
+			WHERE d.task_id = tasks.id AND p.status <> ?
+		)`, entity.TaskStatusCompleted).
+		Updates(map[string]interface{}{
+			"status": entity.TaskStatusPending,
+			"input":  gorm.Expr(dependencyInput),
+		}).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to release dependent tasks: %w", err)
+	}
+
+	return released, nil
+}
+
+// cancelDependents cancels every blocked task that depends on the task,
+// directly or through other blocked tasks, as it can no longer run.
+func (r *taskRepository) cancelDependents(ctx context.Context, id uint64) ([]*entity.Task, error) {
+	var cancelled []*entity.Task
+
+	err := r.db.WithContext(ctx).Raw(`
+		WITH RECURSIVE dependents(id) AS (
+			SELECT task_id FROM task_dependencies WHERE depends_on_id = @id
+			UNION
+			SELECT d.task_id FROM task_dependencies d JOIN dependents ON d.depends_on_id = dependents.id
+		)
+		UPDATE tasks SET status = @cancelled, error = @error, next_run_at = NULL, updated_at = NOW()
+		WHERE id IN (SELECT id FROM dependents) AND status = @blocked
+		RETURNING *`,
+		map[string]interface{}{
+			"id":        id,
+			"cancelled": entity.TaskStatusCancelled,
+			"blocked":   entity.TaskStatusBlocked,
+			"error":     fmt.Sprintf("dependency %d did not complete", id),
+		},
+	).Scan(&cancelled).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to cancel dependent tasks: %w", err)
+	}
+
+	return cancelled, nil
+}
+
+func (r *taskRepository) ResolveBlocked(ctx context.Context) ([]*entity.Task, error) {
+	// The dependencies that did not complete but still have blocked dependents.
+	var failed []uint64
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT DISTINCT d.depends_on_id
+		FROM task_dependencies d
+		JOIN tasks t ON t.id = d.task_id
+		JOIN tasks p ON p.id = d.depends_on_id
+		WHERE t.status = ? AND p.status IN ?`,
+		entity.TaskStatusBlocked,
+		[]entity.TaskStatus{entity.TaskStatusFailed, entity.TaskStatusTimedOut, entity.TaskStatusCancelled},
+	).Scan(&failed).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find blocked tasks: %w", err)
+	}
+
+	var resolved []*entity.Task
+	for _, id := range failed {
+		cancelled, err := r.cancelDependents(ctx, id)
+		if err != nil {
+			return resolved, err
+		}
+
+		resolved = append(resolved, cancelled...)
+	}
+
+	released, err := r.releaseBlocked(ctx, "EXISTS (SELECT 1 FROM task_dependencies WHERE task_id = tasks.id)")
+	if err != nil {
+		return resolved, err
+	}
+
+	return append(resolved, released...), nil
+}
+
+func (r *taskRepository) FindGraph(ctx context.Context, id uint64) ([]*entity.Task, []*entity.TaskDependency, error) {
+	_, err := r.FindByID(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Dependencies are followed both ways, UNION stops at tasks seen before.
+	var ids []uint64
+	err = r.db.WithContext(ctx).Raw(`
+		WITH RECURSIVE graph(id) AS (
+			SELECT CAST(? AS BIGINT)
+			UNION
+			SELECT CASE WHEN d.task_id = graph.id THEN d.depends_on_id ELSE d.task_id END
+			FROM task_dependencies d JOIN graph ON graph.id IN (d.task_id, d.depends_on_id)
+		)
+		SELECT id FROM graph`, id).
+		Scan(&ids).Error
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get task graph: %w", err)
+	}
+
+	var tasks []*entity.Task
+	err = r.model(ctx).Where("id IN ?", ids).Order("id").Find(&tasks).Error
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get task graph: %w", err)
+	}
+
+	var dependencies []*entity.TaskDependency
+	err = r.db.WithContext(ctx).Where("task_id IN ?", ids).Order("task_id, depends_on_id").Find(&dependencies).Error
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get task graph: %w", err)
+	}
+
+	return tasks, dependencies, nil
+}
@@ -0,0 +1,129 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"task-pool/internal/domain/entity"
+	"task-pool/internal/domain/repository"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// heldStatuses are the statuses of a task held by a worker.
+var heldStatuses = []entity.TaskStatus{entity.TaskStatusQueued, entity.TaskStatusRunning}
+
+// Errors recorded on the attempts of tasks taken away from their worker.
+const (
+	errWorkerLost   = "worker stopped sending heartbeats"
+	errWorkerHalted = "worker shut down before the task finished"
+)
+
+func (r *taskRepository) Heartbeat(ctx context.Context, id uint64) error {
+	// UpdateColumn leaves updated_at alone, heartbeats are not changes.
+	result := r.model(ctx).
+		Where("id = ? AND status IN ?", id, heldStatuses).
+		UpdateColumn("heartbeat_at", time.Now())
+	if result.Error != nil {
+		return fmt.Errorf("failed to update task heartbeat: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return repository.ErrTaskLost
+	}
+
+	return nil
+}
+
+func (r *taskRepository) RecoverAbandoned(ctx context.Context, timeout time.Duration, maxAttempts int) ([]*entity.Task, error) {
+	var recovered []*entity.Task
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Raw(`
+			UPDATE tasks SET
+				status = CASE WHEN attempts < COALESCE(NULLIF(max_attempts, 0), @max) THEN @retrying ELSE @failed END,
+				next_run_at = CASE WHEN attempts < COALESCE(NULLIF(max_attempts, 0), @max) THEN NOW() END,
+				dead_lettered_at = CASE WHEN attempts < COALESCE(NULLIF(max_attempts, 0), @max) THEN NULL ELSE NOW() END,
+				error = @error,
+				updated_at = NOW()
+			WHERE status IN @held AND heartbeat_at < @expired
+			RETURNING *`,
+			map[string]interface{}{
+				"max":      maxAttempts,
+				"retrying": entity.TaskStatusRetrying,
+				"failed":   entity.TaskStatusFailed,
+				"error":    errWorkerLost,
+				"held":     heldStatuses,
+				"expired":  time.Now().Add(-timeout),
+			},
+		).Scan(&recovered).Error
+		if err != nil || len(recovered) == 0 {
+			return err
+		}
+
+		ids := make([]uint64, len(recovered))
+		for i, task := range recovered {
+			ids[i] = task.ID
+		}
+
+		return finishOpenAttempts(tx, ids, gorm.Expr("t.error"))
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to recover abandoned tasks: %w", err)
+	}
+
+	return recovered, nil
+}
+
+func (r *taskRepository) Release(ctx context.Context, ids []uint64) ([]*entity.Task, error) {
+	var released []*entity.Task
+	if len(ids) == 0 {
+		return released, nil
+	}
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Raw(`
+			UPDATE tasks SET
+				status = @pending,
+				attempts = GREATEST(attempts - 1, 0),
+				updated_at = NOW()
+			WHERE id IN @ids AND status IN @held
+			RETURNING *`,
+			map[string]interface{}{
+				"pending": entity.TaskStatusPending,
+				"ids":     ids,
+				"held":    heldStatuses,
+			},
+		).Scan(&released).Error
+		if err != nil || len(released) == 0 {
+			return err
+		}
+
+		releasedIDs := make([]uint64, len(released))
+		for i, task := range released {
+			releasedIDs[i] = task.ID
+		}
+
+		return finishOpenAttempts(tx, releasedIDs, errWorkerHalted)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to release tasks: %w", err)
+	}
+
+	return released, nil
+}
+
+// finishOpenAttempts closes the unfinished attempts of the given tasks with
+// the current status of the task as outcome and the given error.
+func finishOpenAttempts(tx *gorm.DB, ids []uint64, taskErr interface{}) error {
+	return tx.Exec(`
+		UPDATE task_attempts a SET
+			finished_at = NOW(),
+			duration = (EXTRACT(EPOCH FROM NOW() - a.started_at) * 1000000000)::bigint,
+			outcome = t.status,
+			error = ?
+		FROM tasks t
+		WHERE a.task_id = t.id AND t.id IN ? AND a.finished_at IS NULL`,
+		taskErr, ids,
+	).Error
+}
@@ -0,0 +1,73 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"task-pool/internal/domain/entity"
+	"task-pool/internal/domain/repository"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type workflowRepository struct {
+	db *gorm.DB
+}
+
+func NewWorkflowRepository(db *gorm.DB) repository.WorkflowRepository {
+	return &workflowRepository{db: db}
+}
+
+func (r *workflowRepository) Create(ctx context.Context, workflow *entity.Workflow) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Omit(clause.Associations).Create(workflow).Error
+		if err != nil {
+			return fmt.Errorf("failed to create workflow: %w", err)
+		}
+
+		var previous []uint64
+		for _, step := range workflow.Steps() {
+			ids := make([]uint64, 0, len(step))
+
+			for _, task := range step {
+				task.WorkflowID = &workflow.ID
+
+				if len(previous) == 0 {
+					err = insertTask(tx.Model(&entity.Task{}), task)
+				} else {
+					err = createWithDependencies(tx, task, previous)
+				}
+				if err != nil {
+					return err
+				}
+
+				ids = append(ids, task.ID)
+			}
+
+			previous = ids
+		}
+
+		return nil
+	})
+}
+
+func (r *workflowRepository) FindByID(ctx context.Context, id uint64) (*entity.Workflow, error) {
+	var workflow entity.Workflow
+
+	err := r.db.WithContext(ctx).
+		Preload("Tasks", func(db *gorm.DB) *gorm.DB {
+			return db.Order("workflow_step, id")
+		}).
+		Where("id = ?", id).
+		First(&workflow).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, repository.ErrWorkflowNotFound
+		}
+
+		return nil, fmt.Errorf("failed to get workflow: %w", err)
+	}
+
+	return &workflow, nil
+}
@@ -0,0 +1,14 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Duration is a time.Duration written to JSON in the format it is accepted
+// in, the one of time.ParseDuration such as "30s".
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
@@ -0,0 +1,90 @@
+package entity
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// ErrCronNeverFires is returned for a valid cron expression that has no tick,
+// such as one for February 30th.
+var ErrCronNeverFires = errors.New("cron expression never fires")
+
+// Schedule creates a new task from its template on every tick of a cron
+// expression.
+type Schedule struct {
+	ID      uint64 `gorm:"primaryKey"`
+	Name    string `gorm:"uniqueIndex"`
+	Cron    string
+	Enabled bool
+
+	// Template of the tasks created by the schedule
+	Title       string
+	Description string
+	Type        string
+	Payload     json.RawMessage `gorm:"type:jsonb" swaggertype:"object"`
+
+	NextRunAt time.Time `gorm:"index"`
+	LastRunAt *time.Time
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func NewSchedule(name, cronExpr, title, description, taskType string, payload json.RawMessage) (*Schedule, error) {
+	s := &Schedule{
+		Name:        name,
+		Cron:        cronExpr,
+		Enabled:     true,
+		Title:       title,
+		Description: description,
+		Type:        taskType,
+		Payload:     payload,
+	}
+
+	if err := s.Advance(time.Now()); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (Schedule) TableName() string {
+	return "schedules"
+}
+
+// Advance moves NextRunAt to the first tick of the cron expression after now.
+// Ticks missed while nothing was running are skipped. It returns
+// ErrCronNeverFires, and leaves NextRunAt alone, when there is no such tick.
+func (s *Schedule) Advance(now time.Time) error {
+	spec, err := cron.ParseStandard(s.Cron)
+	if err != nil {
+		return fmt.Errorf("invalid cron expression %q: %w", s.Cron, err)
+	}
+
+	next := spec.Next(now)
+	if next.IsZero() {
+		return fmt.Errorf("%w: %q", ErrCronNeverFires, s.Cron)
+	}
+
+	s.NextRunAt = next
+
+	return nil
+}
+
+// Fire builds the task for the current tick and advances the schedule.
+func (s *Schedule) Fire(now time.Time) (*Task, error) {
+	if err := s.Advance(now); err != nil {
+		return nil, err
+	}
+
+	s.LastRunAt = &now
+
+	task := NewTask(s.Title, s.Description, s.Type, s.Payload, TaskStatusPending)
+	task.ScheduleID = &s.ID
+
+	return task, nil
+}
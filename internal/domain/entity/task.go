@@ -1,32 +1,144 @@
 package entity
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
 	"time"
 )
 
 type TaskStatus string
 
 const (
+	TaskStatusBlocked   TaskStatus = "blocked"
 	TaskStatusPending   TaskStatus = "pending"
+	TaskStatusQueued    TaskStatus = "queued"
+	TaskStatusRunning   TaskStatus = "running"
+	TaskStatusRetrying  TaskStatus = "retrying"
 	TaskStatusCompleted TaskStatus = "completed"
 	TaskStatusFailed    TaskStatus = "failed"
+	TaskStatusTimedOut  TaskStatus = "timed_out"
+	TaskStatusCancelled TaskStatus = "cancelled"
 )
 
+var ErrInvalidTransition = errors.New("invalid task status transition")
+
+// IsValid reports whether s is one of the known task statuses.
+func (s TaskStatus) IsValid() bool {
+	switch s {
+	case TaskStatusBlocked, TaskStatusPending, TaskStatusQueued, TaskStatusRunning, TaskStatusRetrying,
+		TaskStatusCompleted, TaskStatusFailed, TaskStatusTimedOut, TaskStatusCancelled:
+		return true
+	}
+
+	return false
+}
+
+// transitions lists the statuses a task may move to from each status.
+// Completed and cancelled tasks are final, failed and timed out tasks can
+// only be requeued. Blocked tasks wait for their dependencies to complete.
+var transitions = map[TaskStatus][]TaskStatus{
+	TaskStatusBlocked:  {TaskStatusPending, TaskStatusCancelled},
+	TaskStatusPending:  {TaskStatusQueued, TaskStatusCancelled},
+	TaskStatusQueued:   {TaskStatusRunning, TaskStatusPending, TaskStatusCancelled},
+	TaskStatusRunning:  {TaskStatusCompleted, TaskStatusFailed, TaskStatusRetrying, TaskStatusTimedOut, TaskStatusCancelled, TaskStatusPending},
+	TaskStatusRetrying: {TaskStatusQueued, TaskStatusCancelled},
+	TaskStatusFailed:   {TaskStatusPending},
+	TaskStatusTimedOut: {TaskStatusPending},
+}
+
+// TransitionsTo returns the statuses a task may move to status to from,
+// sorted for stable queries.
+func TransitionsTo(to TaskStatus) []TaskStatus {
+	var from []TaskStatus
+	for status := range transitions {
+		if CanTransition(status, to) {
+			from = append(from, status)
+		}
+	}
+
+	slices.Sort(from)
+
+	return from
+}
+
+// CanTransition reports whether a task in status from may move to status to.
+func CanTransition(from, to TaskStatus) bool {
+	return slices.Contains(transitions[from], to)
+}
+
+// DefaultQueue receives the tasks that do not name a queue.
+const DefaultQueue = "default"
+
 type Task struct {
-	ID          uint64 `gorm:"primaryKey"`
+	ID          uint64 `gorm:"primaryKey;index:idx_tasks_claim,priority:4"`
 	Title       string
 	Description string
-	Status      TaskStatus
+	Type        string          `gorm:"index"`
+	Queue       string          `gorm:"index;index:idx_tasks_claim,priority:1;not null;default:'default'"`
+	Payload     json.RawMessage `gorm:"type:jsonb" swaggertype:"object"`
+	Status      TaskStatus      `gorm:"index:idx_tasks_claim,priority:2"`
+	Error       string
+
+	// Input holds the results of the dependencies once they have completed:
+	// the result of the only dependency, or an array of the results ordered
+	// by dependency ID.
+	Input json.RawMessage `gorm:"type:jsonb" swaggertype:"object"`
+
+	// Result holds the JSON value returned by the handler of a completed task.
+	Result json.RawMessage `gorm:"type:jsonb" swaggertype:"object"`
+
+	// Progress is the percentage of the current attempt reported done by the
+	// handler, ProgressMessage describes the step it is at.
+	Progress        int
+	ProgressMessage string
+
+	// Priority orders due tasks, higher values are claimed first. Claims are
+	// served by idx_tasks_claim on queue, status, priority and ID.
+	Priority int `gorm:"index;index:idx_tasks_claim,priority:3,sort:desc"`
+
+	// Attempts counts the executions started so far. MaxAttempts overrides
+	// the worker retry policy when greater than zero.
+	Attempts    int
+	MaxAttempts int
+	NextRunAt   *time.Time `gorm:"index"`
+	StartedAt   *time.Time
+
+	// HeartbeatAt is refreshed by the worker holding the task while it is
+	// queued or running, a stale heartbeat means that worker is gone.
+	HeartbeatAt *time.Time `gorm:"index"`
+
+	// Timeout overrides the worker execution timeout when greater than zero.
+	Timeout Duration `swaggertype:"string" example:"30s"`
+
+	// DeadLetteredAt is set when the worker gives up on the task.
+	DeadLetteredAt *time.Time `gorm:"index"`
+
+	// IdempotencyKey identifies the create request of the task, a request
+	// repeated with the same key returns this task instead of a new one.
+	IdempotencyKey *string `gorm:"uniqueIndex"`
+
+	// ScheduleID references the schedule that created the task, if any.
+	ScheduleID *uint64 `gorm:"index"`
+
+	// WorkflowID references the workflow the task is a step of, if any.
+	// WorkflowStep is the position of that step, starting at 0.
+	WorkflowID   *uint64 `gorm:"index"`
+	WorkflowStep int
 
 	CreatedAt time.Time
 	UpdatedAt time.Time
 }
 
-func NewTask(title, description string, status TaskStatus) *Task {
+func NewTask(title, description, taskType string, payload json.RawMessage, status TaskStatus) *Task {
 	return &Task{
 		Title:       title,
 		Status:      status,
 		Description: description,
+		Type:        taskType,
+		Queue:       DefaultQueue,
+		Payload:     payload,
 	}
 }
 
@@ -34,10 +146,122 @@ func (Task) TableName() string {
 	return "tasks"
 }
 
-func (t *Task) Complete() {
-	t.Status = TaskStatusCompleted
+func (t *Task) transition(to TaskStatus) error {
+	if !CanTransition(t.Status, to) {
+		return fmt.Errorf("%w: %s -> %s", ErrInvalidTransition, t.Status, to)
+	}
+
+	t.Status = to
+
+	return nil
+}
+
+// Start marks a queued task as running.
+func (t *Task) Start(now time.Time) error {
+	if err := t.transition(TaskStatusRunning); err != nil {
+		return err
+	}
+
+	t.StartedAt = &now
+	t.Progress = 0
+	t.ProgressMessage = ""
+
+	return nil
+}
+
+// Complete records the result of the handler.
+func (t *Task) Complete(result json.RawMessage) error {
+	if err := t.transition(TaskStatusCompleted); err != nil {
+		return err
+	}
+
+	t.Error = ""
+	t.Result = result
+	t.Progress = 100
+
+	return nil
+}
+
+// ReportProgress records the progress of a running task, percent is clamped
+// to 0-100.
+func (t *Task) ReportProgress(percent int, message string) {
+	t.Progress = min(max(percent, 0), 100)
+	t.ProgressMessage = message
+}
+
+// Release returns a claimed task to pending without counting the attempt.
+func (t *Task) Release() error {
+	if err := t.transition(TaskStatusPending); err != nil {
+		return err
+	}
+
+	if t.Attempts > 0 {
+		t.Attempts--
+	}
+
+	return nil
+}
+
+// Retry records the failure and reschedules the task to run again at nextRunAt.
+func (t *Task) Retry(err error, nextRunAt time.Time) error {
+	if tErr := t.transition(TaskStatusRetrying); tErr != nil {
+		return tErr
+	}
+
+	t.Error = err.Error()
+	t.NextRunAt = &nextRunAt
+
+	return nil
+}
+
+// Failed records the error and moves the task to the dead-letter queue.
+func (t *Task) Failed(err error) error {
+	return t.deadLetter(TaskStatusFailed, err)
 }
 
-func (t *Task) Failed() {
-	t.Status = TaskStatusFailed
+// TimedOut records a timeout of the last attempt and moves the task to the
+// dead-letter queue.
+func (t *Task) TimedOut(err error) error {
+	return t.deadLetter(TaskStatusTimedOut, err)
+}
+
+func (t *Task) deadLetter(status TaskStatus, err error) error {
+	if tErr := t.transition(status); tErr != nil {
+		return tErr
+	}
+
+	now := time.Now()
+
+	t.Error = err.Error()
+	t.DeadLetteredAt = &now
+
+	return nil
+}
+
+func (t *Task) IsDeadLettered() bool {
+	return t.DeadLetteredAt != nil
+}
+
+// Cancel stops the task for good, it is never claimed again.
+func (t *Task) Cancel() error {
+	if err := t.transition(TaskStatusCancelled); err != nil {
+		return err
+	}
+
+	t.NextRunAt = nil
+
+	return nil
+}
+
+// Requeue takes the task out of the dead-letter queue with a fresh set of attempts.
+func (t *Task) Requeue() error {
+	if err := t.transition(TaskStatusPending); err != nil {
+		return err
+	}
+
+	t.Attempts = 0
+	t.NextRunAt = nil
+	t.DeadLetteredAt = nil
+
+	return nil
 }
@@ -0,0 +1,45 @@
+package entity
+
+import "time"
+
+// TaskAttempt records a single execution of a task.
+type TaskAttempt struct {
+	ID       uint64 `gorm:"primaryKey"`
+	TaskID   uint64 `gorm:"index"`
+	Task     *Task  `gorm:"constraint:OnDelete:CASCADE" json:"-" swaggerignore:"true"`
+	Attempt  int
+	WorkerID string
+
+	StartedAt  time.Time
+	FinishedAt *time.Time
+	Duration   time.Duration `swaggertype:"integer"`
+
+	// Outcome is the status the task moved to after the attempt, running
+	// while the attempt is in progress.
+	Outcome TaskStatus
+	Error   string
+}
+
+func NewTaskAttempt(task *Task, workerID string, startedAt time.Time) *TaskAttempt {
+	return &TaskAttempt{
+		TaskID:    task.ID,
+		Attempt:   task.Attempts,
+		WorkerID:  workerID,
+		StartedAt: startedAt,
+		Outcome:   TaskStatusRunning,
+	}
+}
+
+func (TaskAttempt) TableName() string {
+	return "task_attempts"
+}
+
+// Finish records the outcome of the attempt.
+func (a *TaskAttempt) Finish(outcome TaskStatus, err error, finishedAt time.Time) {
+	a.FinishedAt = &finishedAt
+	a.Duration = finishedAt.Sub(a.StartedAt)
+	a.Outcome = outcome
+	if err != nil {
+		a.Error = err.Error()
+	}
+}
@@ -0,0 +1,14 @@
+package entity
+
+// TaskDependency holds TaskID back until DependsOnID has completed.
+type TaskDependency struct {
+	TaskID      uint64 `gorm:"primaryKey"`
+	DependsOnID uint64 `gorm:"primaryKey;index"`
+
+	Task      *Task `gorm:"constraint:OnDelete:CASCADE" json:"-" swaggerignore:"true"`
+	DependsOn *Task `gorm:"foreignKey:DependsOnID;constraint:OnDelete:CASCADE" json:"-" swaggerignore:"true"`
+}
+
+func (TaskDependency) TableName() string {
+	return "task_dependencies"
+}
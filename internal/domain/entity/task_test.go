@@ -0,0 +1,123 @@
+package entity
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTask_Transitions(t *testing.T) {
+	t.Run("lifecycle of a task that succeeds after a retry", func(t *testing.T) {
+		task := NewTask("title", "description", "test", nil, TaskStatusPending)
+
+		task.Status = TaskStatusQueued
+		require.NoError(t, task.Start(time.Now()))
+		assert.NotNil(t, task.StartedAt)
+
+		require.NoError(t, task.Retry(errors.New("smtp unavailable"), time.Now().Add(time.Second)))
+		assert.Equal(t, TaskStatusRetrying, task.Status)
+
+		task.Status = TaskStatusQueued
+		require.NoError(t, task.Start(time.Now()))
+		require.NoError(t, task.Complete([]byte(`{"sent":true}`)))
+		assert.Equal(t, TaskStatusCompleted, task.Status)
+		assert.Empty(t, task.Error)
+		assert.JSONEq(t, `{"sent":true}`, string(task.Result))
+	})
+
+	t.Run("illegal transitions are rejected", func(t *testing.T) {
+		tests := []struct {
+			from       TaskStatus
+			transition func(task *Task) error
+		}{
+			{TaskStatusCompleted, func(task *Task) error { return task.Start(time.Now()) }},
+			{TaskStatusPending, func(task *Task) error { return task.Complete(nil) }},
+			{TaskStatusCancelled, func(task *Task) error { return task.Requeue() }},
+			{TaskStatusCompleted, func(task *Task) error { return task.Cancel() }},
+			{TaskStatusRetrying, func(task *Task) error { return task.Failed(errors.New("boom")) }},
+			{TaskStatusBlocked, func(task *Task) error { return task.Start(time.Now()) }},
+		}
+
+		for _, tt := range tests {
+			task := &Task{Status: tt.from}
+
+			err := tt.transition(task)
+
+			require.ErrorIs(t, err, ErrInvalidTransition, tt.from)
+			assert.Equal(t, tt.from, task.Status)
+		}
+	})
+
+	t.Run("timed out task is dead-lettered and can be requeued", func(t *testing.T) {
+		task := &Task{Status: TaskStatusRunning, Attempts: 3}
+
+		require.NoError(t, task.TimedOut(errors.New("task timed out after 1s")))
+		assert.Equal(t, TaskStatusTimedOut, task.Status)
+		assert.True(t, task.IsDeadLettered())
+
+		require.NoError(t, task.Requeue())
+		assert.Equal(t, TaskStatusPending, task.Status)
+		assert.Equal(t, 0, task.Attempts)
+		assert.False(t, task.IsDeadLettered())
+	})
+
+	t.Run("blocked task is released or cancelled", func(t *testing.T) {
+		assert.True(t, CanTransition(TaskStatusBlocked, TaskStatusPending))
+		assert.False(t, CanTransition(TaskStatusBlocked, TaskStatusQueued))
+
+		task := &Task{Status: TaskStatusBlocked}
+		require.NoError(t, task.Cancel())
+		assert.Equal(t, TaskStatusCancelled, task.Status)
+	})
+
+	t.Run("progress of an attempt", func(t *testing.T) {
+		task := &Task{Status: TaskStatusQueued, Progress: 70, ProgressMessage: "uploading"}
+
+		require.NoError(t, task.Start(time.Now()))
+		assert.Equal(t, 0, task.Progress)
+		assert.Empty(t, task.ProgressMessage)
+
+		task.ReportProgress(120, "resizing")
+		assert.Equal(t, 100, task.Progress)
+		task.ReportProgress(-1, "resizing")
+		assert.Equal(t, 0, task.Progress)
+
+		require.NoError(t, task.Complete(nil))
+		assert.Equal(t, 100, task.Progress)
+		assert.Equal(t, "resizing", task.ProgressMessage)
+	})
+
+	t.Run("transitions to a status", func(t *testing.T) {
+		assert.Equal(t, []TaskStatus{TaskStatusPending, TaskStatusRetrying}, TransitionsTo(TaskStatusQueued))
+		assert.Equal(t,
+			[]TaskStatus{TaskStatusBlocked, TaskStatusPending, TaskStatusQueued, TaskStatusRetrying, TaskStatusRunning},
+			TransitionsTo(TaskStatusCancelled),
+		)
+	})
+}
+
+func TestTaskStatus_IsValid(t *testing.T) {
+	assert.True(t, TaskStatusTimedOut.IsValid())
+	assert.True(t, TaskStatusCancelled.IsValid())
+	assert.True(t, TaskStatusBlocked.IsValid())
+	assert.False(t, TaskStatus("done").IsValid())
+	assert.False(t, TaskStatus("").IsValid())
+}
+
+func TestTask_JSON(t *testing.T) {
+	t.Run("timeout is written in the format it is accepted in", func(t *testing.T) {
+		task := NewTask("title", "description", "test", nil, TaskStatusPending)
+		task.Timeout = Duration(90 * time.Second)
+
+		data, err := json.Marshal(task)
+		require.NoError(t, err)
+
+		var fields map[string]any
+		require.NoError(t, json.Unmarshal(data, &fields))
+		assert.Equal(t, "1m30s", fields["Timeout"])
+	})
+}
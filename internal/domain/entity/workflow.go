@@ -0,0 +1,100 @@
+package entity
+
+import "time"
+
+type WorkflowStatus string
+
+const (
+	WorkflowStatusPending   WorkflowStatus = "pending"
+	WorkflowStatusRunning   WorkflowStatus = "running"
+	WorkflowStatusCompleted WorkflowStatus = "completed"
+	WorkflowStatusFailed    WorkflowStatus = "failed"
+	WorkflowStatusCancelled WorkflowStatus = "cancelled"
+)
+
+// Workflow runs its tasks step after step. The tasks of a step run in
+// parallel and depend on every task of the previous step, whose results they
+// receive as input.
+type Workflow struct {
+	ID    uint64 `gorm:"primaryKey"`
+	Name  string
+	Tasks []*Task `json:"-" swaggerignore:"true"`
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func NewWorkflow(name string, steps [][]*Task) *Workflow {
+	w := &Workflow{Name: name}
+
+	for i, step := range steps {
+		for _, task := range step {
+			task.WorkflowStep = i
+			w.Tasks = append(w.Tasks, task)
+		}
+	}
+
+	return w
+}
+
+func (Workflow) TableName() string {
+	return "workflows"
+}
+
+// Steps groups the tasks of the workflow by step.
+func (w *Workflow) Steps() [][]*Task {
+	var steps [][]*Task
+
+	for _, task := range w.Tasks {
+		for len(steps) <= task.WorkflowStep {
+			steps = append(steps, nil)
+		}
+
+		steps[task.WorkflowStep] = append(steps[task.WorkflowStep], task)
+	}
+
+	return steps
+}
+
+// Status derives the status of the workflow from its tasks. A failed task
+// fails the workflow, as the tasks after it are cancelled.
+func (w *Workflow) Status() WorkflowStatus {
+	var completed, waiting int
+	cancelled := false
+
+	for _, task := range w.Tasks {
+		switch task.Status {
+		case TaskStatusFailed, TaskStatusTimedOut:
+			return WorkflowStatusFailed
+		case TaskStatusCancelled:
+			cancelled = true
+		case TaskStatusCompleted:
+			completed++
+		case TaskStatusBlocked, TaskStatusPending:
+			waiting++
+		}
+	}
+
+	switch {
+	case cancelled:
+		return WorkflowStatusCancelled
+	case completed == len(w.Tasks):
+		return WorkflowStatusCompleted
+	case waiting == len(w.Tasks):
+		return WorkflowStatusPending
+	}
+
+	return WorkflowStatusRunning
+}
+
+// Progress returns the number of completed tasks of the workflow and the
+// number of its tasks.
+func (w *Workflow) Progress() (completed, total int) {
+	for _, task := range w.Tasks {
+		if task.Status == TaskStatusCompleted {
+			completed++
+		}
+	}
+
+	return completed, len(w.Tasks)
+}
@@ -0,0 +1,51 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkflow_Status(t *testing.T) {
+	workflow := func(statuses ...TaskStatus) *Workflow {
+		w := &Workflow{}
+		for i, status := range statuses {
+			w.Tasks = append(w.Tasks, &Task{ID: uint64(i + 1), WorkflowStep: i, Status: status})
+		}
+		return w
+	}
+
+	tests := []struct {
+		name     string
+		workflow *Workflow
+		want     WorkflowStatus
+	}{
+		{"nothing started", workflow(TaskStatusPending, TaskStatusBlocked), WorkflowStatusPending},
+		{"first step running", workflow(TaskStatusRunning, TaskStatusBlocked), WorkflowStatusRunning},
+		{"between steps", workflow(TaskStatusCompleted, TaskStatusPending), WorkflowStatusRunning},
+		{"all steps completed", workflow(TaskStatusCompleted, TaskStatusCompleted), WorkflowStatusCompleted},
+		{"failed step cancels the rest", workflow(TaskStatusTimedOut, TaskStatusCancelled), WorkflowStatusFailed},
+		{"cancelled", workflow(TaskStatusCompleted, TaskStatusCancelled), WorkflowStatusCancelled},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.workflow.Status())
+		})
+	}
+}
+
+func TestWorkflow_Steps(t *testing.T) {
+	first := NewTask("Download", "d", "sleep", nil, TaskStatusPending)
+	left := NewTask("Resize small", "d", "sleep", nil, TaskStatusPending)
+	right := NewTask("Resize large", "d", "sleep", nil, TaskStatusPending)
+
+	w := NewWorkflow("thumbnails", [][]*Task{{first}, {left, right}})
+
+	assert.Equal(t, [][]*Task{{first}, {left, right}}, w.Steps())
+	assert.Equal(t, 1, right.WorkflowStep)
+
+	completed, total := w.Progress()
+	assert.Equal(t, 0, completed)
+	assert.Equal(t, 3, total)
+}
@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"task-pool/internal/domain/entity"
+	"time"
+)
+
+var (
+	ErrScheduleNotFound      = errors.New("schedule not found")
+	ErrScheduleAlreadyExists = errors.New("schedule already exists")
+)
+
+type ScheduleRepository interface {
+	Create(ctx context.Context, schedule *entity.Schedule) error
+	FindByID(ctx context.Context, id uint64) (*entity.Schedule, error)
+	FindAll(ctx context.Context) ([]*entity.Schedule, error)
+	Update(ctx context.Context, schedule *entity.Schedule) error
+	Delete(ctx context.Context, id uint64) error
+
+	// FireDue locks the enabled schedules that are due at now and passes each
+	// one to fire. The returned task, if any, is created and the schedule is
+	// saved in the same transaction, so a tick fires exactly once even when
+	// several replicas call FireDue concurrently. It returns the created tasks.
+	FireDue(ctx context.Context, now time.Time, fire func(schedule *entity.Schedule) *entity.Task) ([]*entity.Task, error)
+}
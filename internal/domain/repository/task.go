@@ -4,15 +4,156 @@ import (
 	"context"
 	"errors"
 	"task-pool/internal/domain/entity"
+	"time"
 )
 
 var (
-	ErrTaskNotFound = errors.New("task not found")
+	ErrTaskNotFound       = errors.New("task not found")
+	ErrNoTaskAvailable    = errors.New("no task available")
+	ErrTaskNotCancellable = errors.New("task has already finished")
+	ErrTaskCancelled      = errors.New("task has been cancelled")
+	ErrTaskLost           = errors.New("task is no longer held by this worker")
+	ErrTaskExists         = errors.New("task with this idempotency key already exists")
+	ErrInvalidCursor      = errors.New("invalid cursor")
+	ErrDependencyNotFound = errors.New("dependency not found")
+	ErrDependencyFailed   = errors.New("dependency did not complete")
 )
 
+// Columns tasks can be listed by.
+const (
+	TaskSortID        = "id"
+	TaskSortCreatedAt = "created_at"
+	TaskSortUpdatedAt = "updated_at"
+	TaskSortPriority  = "priority"
+)
+
+var TaskSorts = []string{TaskSortID, TaskSortCreatedAt, TaskSortUpdatedAt, TaskSortPriority}
+
+// TaskFilter selects a page of tasks, zero fields do not filter.
+type TaskFilter struct {
+	Status        entity.TaskStatus
+	Type          string
+	Queue         string
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+
+	// Sort is one of TaskSorts, ties are broken by ID in the same direction.
+	Sort string
+	Desc bool
+
+	// Cursor continues the listing after the last task of the previous page,
+	// it is only valid with the sort it was returned for.
+	Cursor string
+	Limit  int
+}
+
+type TaskPage struct {
+	Tasks []*entity.Task
+	// NextCursor is empty on the last page.
+	NextCursor string
+	// Total counts the tasks matching the filter across all pages.
+	Total int64
+}
+
 type TaskRepository interface {
+	// Create inserts the task. It returns ErrTaskExists, and inserts
+	// nothing, when a task with the same idempotency key already exists.
 	Create(ctx context.Context, task *entity.Task) error
+
+	// CreateBatch inserts all tasks in one transaction, either every task is
+	// created and gets its ID or none is.
+	CreateBatch(ctx context.Context, tasks []*entity.Task) error
+
+	// CreateWithDependencies inserts the task so that it only runs once every
+	// task of dependsOn has completed, it is created blocked unless they all
+	// have completed already. It returns ErrDependencyNotFound when one of
+	// them does not exist, ErrDependencyFailed when one has failed, timed out
+	// or been cancelled, and ErrTaskExists like Create.
+	CreateWithDependencies(ctx context.Context, task *entity.Task, dependsOn []uint64) error
+
+	// ResolveDependents hands the final status of task down to the tasks
+	// blocked on it. Once task has completed, the dependents whose
+	// dependencies have all completed are moved to pending. When task has
+	// failed, timed out or been cancelled, every blocked task depending on it,
+	// directly or not, is cancelled. It returns the dependents it changed.
+	ResolveDependents(ctx context.Context, task *entity.Task) ([]*entity.Task, error)
+
+	// ResolveBlocked resolves the blocked tasks whose dependencies have
+	// finished, the ones ResolveDependents missed because it failed after the
+	// status of a dependency was saved. It returns the tasks it changed.
+	ResolveBlocked(ctx context.Context) ([]*entity.Task, error)
+
+	// FindGraph returns the tasks connected to the task through dependencies,
+	// the task included, and the dependencies between them.
+	FindGraph(ctx context.Context, id uint64) ([]*entity.Task, []*entity.TaskDependency, error)
+
 	FindByID(ctx context.Context, id uint64) (*entity.Task, error)
-	FindAll(ctx context.Context) ([]*entity.Task, error)
-	Update(ctx context.Context, task *entity.Task) error
+	FindByIdempotencyKey(ctx context.Context, key string) (*entity.Task, error)
+
+	// FindAll returns a page of the tasks matching filter. It returns
+	// ErrInvalidCursor when the cursor is malformed or belongs to another
+	// sort.
+	FindAll(ctx context.Context, filter TaskFilter) (*TaskPage, error)
+
+	// Update saves a task claimed by a worker, as long as that worker still
+	// holds it: the task is queued or running on the given attempt. It
+	// returns ErrTaskCancelled when the task has been cancelled meanwhile,
+	// ErrTaskLost when it has been recovered from the worker since, e.g.
+	// because its heartbeat lapsed, and ErrTaskNotFound when it does not
+	// exist.
+	Update(ctx context.Context, task *entity.Task, attempt int) error
+
+	// UpdateProgress saves the progress of a task while it is running.
+	UpdateProgress(ctx context.Context, id uint64, progress int, message string) error
+
+	// ClaimNext atomically picks the due pending or retrying task of queue with
+	// the highest priority, marks it as queued with a fresh heartbeat, counts
+	// the attempt and returns it.
+	// Every aging interval a task has been due raises its priority by one,
+	// aging <= 0 disables it. Concurrent callers never receive the same task.
+	// It returns ErrNoTaskAvailable when there is nothing to claim.
+	ClaimNext(ctx context.Context, queue string, aging time.Duration) (*entity.Task, error)
+
+	// Heartbeat tells that the worker holding the task, queued or running,
+	// is still alive. It returns ErrTaskLost when the task is no longer
+	// queued or running.
+	Heartbeat(ctx context.Context, id uint64) error
+
+	// RecoverAbandoned hands back the queued or running tasks whose heartbeat
+	// is older than timeout, their worker is gone. A task with attempts left,
+	// per its MaxAttempts or else maxAttempts, is retried right away, the
+	// others fail and are dead-lettered. Their open attempt is closed with
+	// the same outcome. It returns the recovered tasks.
+	RecoverAbandoned(ctx context.Context, timeout time.Duration, maxAttempts int) ([]*entity.Task, error)
+
+	// Release returns the given tasks that are still queued or running to
+	// pending without counting their attempt, and closes their open attempt.
+	// It returns the released tasks.
+	Release(ctx context.Context, ids []uint64) ([]*entity.Task, error)
+
+	// Cancel marks a task that has not finished yet as cancelled and returns
+	// it. It returns ErrTaskNotCancellable when the task has already finished.
+	Cancel(ctx context.Context, id uint64) (*entity.Task, error)
+
+	// FindCancelled returns which of the given tasks have been cancelled.
+	FindCancelled(ctx context.Context, ids []uint64) ([]uint64, error)
+
+	// CountPending returns the number of pending or retrying tasks of queue
+	// that are due.
+	CountPending(ctx context.Context, queue string) (int64, error)
+
+	// FindNextRunAt returns the earliest run time of the pending or retrying
+	// tasks that are not due yet, or nil when there is none.
+	FindNextRunAt(ctx context.Context) (*time.Time, error)
+
+	// FindDeadLettered returns the tasks the worker gave up on, oldest first.
+	FindDeadLettered(ctx context.Context) ([]*entity.Task, error)
+
+	// RequeueDeadLettered moves dead-lettered tasks back to pending with their
+	// attempts reset and returns them. When ids is empty every dead-lettered
+	// task is requeued.
+	RequeueDeadLettered(ctx context.Context, ids []uint64) ([]*entity.Task, error)
+
+	// PurgeDeadLettered deletes every dead-lettered task.
+	PurgeDeadLettered(ctx context.Context) (int64, error)
 }
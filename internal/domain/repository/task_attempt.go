@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"context"
+	"task-pool/internal/domain/entity"
+)
+
+type TaskAttemptRepository interface {
+	Create(ctx context.Context, attempt *entity.TaskAttempt) error
+	Update(ctx context.Context, attempt *entity.TaskAttempt) error
+
+	// FindByTaskID returns the attempts of a task, oldest first.
+	FindByTaskID(ctx context.Context, taskID uint64) ([]*entity.TaskAttempt, error)
+}
@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"task-pool/internal/domain/entity"
+)
+
+var ErrWorkflowNotFound = errors.New("workflow not found")
+
+type WorkflowRepository interface {
+	// Create inserts the workflow and its tasks in one transaction. The tasks
+	// of every step depend on all tasks of the previous step.
+	Create(ctx context.Context, workflow *entity.Workflow) error
+
+	// FindByID returns the workflow with its tasks ordered by step.
+	FindByID(ctx context.Context, id uint64) (*entity.Workflow, error)
+}
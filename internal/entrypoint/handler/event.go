@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"task-pool/internal/event"
+	"task-pool/internal/service/contracts"
+	"task-pool/pkg/apperror"
+	"task-pool/pkg/logger"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// keepAliveInterval is how often an idle stream sends a comment, it keeps
+// proxies from closing the connection and detects clients that went away.
+const keepAliveInterval = 15 * time.Second
+
+type EventHandler struct {
+	taskService contracts.TaskService
+	bus         *event.Bus
+}
+
+func NewEventHandler(taskService contracts.TaskService, bus *event.Bus) *EventHandler {
+	return &EventHandler{taskService: taskService, bus: bus}
+}
+
+// TaskEvents streams the changes of a task
+//
+//	@Summary		Stream task events
+//	@Description	Stream the status, progress and result changes of a task as server-sent events. The current state of the task is sent first as a status event
+//	@Tags			events
+//	@Produce		text/event-stream
+//	@Param			id	path		uint64				true	"Task ID"
+//	@Success		200	{object}	event.TaskEvent		"Stream of task events"
+//	@Failure		400	{object}	map[string]string	"Bad request - invalid ID format"
+//	@Failure		404	{object}	map[string]string	"Task not found"
+//	@Failure		500	{object}	map[string]string	"Internal server error"
+//	@Router			/api/v1/tasks/{id}/events [get]
+func (h *EventHandler) TaskEvents(c fiber.Ctx) error {
+	id, err := parseID(c)
+	if err != nil {
+		return apperror.HandleError(c, err)
+	}
+
+	// Subscribe before reading the task so that no change falls in between.
+	subscription := h.bus.Subscribe(id)
+
+	task, err := h.taskService.GetByID(c.Context(), id)
+	if err != nil {
+		subscription.Close()
+		return apperror.HandleError(c, err)
+	}
+
+	return stream(c, subscription, event.NewTaskEvent(event.TypeStatus, task))
+}
+
+// Events streams the changes of every task
+//
+//	@Summary		Stream events of all tasks
+//	@Description	Stream the status, progress and result changes of every task as server-sent events
+//	@Tags			events
+//	@Produce		text/event-stream
+//	@Success		200	{object}	event.TaskEvent	"Stream of task events"
+//	@Router			/api/v1/events [get]
+func (h *EventHandler) Events(c fiber.Ctx) error {
+	return stream(c, h.bus.Subscribe(0))
+}
+
+// stream writes the initial events and then the events of subscription until
+// the client goes away or the subscription ends, a client dropped for falling
+// behind is expected to reconnect.
+func stream(c fiber.Ctx, subscription *event.Subscription, initial ...event.TaskEvent) error {
+	c.Set(fiber.HeaderContentType, "text/event-stream")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set(fiber.HeaderConnection, "keep-alive")
+	c.Set("X-Accel-Buffering", "no")
+
+	return c.SendStreamWriter(func(w *bufio.Writer) {
+		defer subscription.Close()
+
+		// The response headers are only sent along with the first bytes of
+		// the body, a comment gets them to the client right away.
+		_, err := w.WriteString(": connected\n\n")
+		if err != nil || w.Flush() != nil {
+			return
+		}
+
+		for _, e := range initial {
+			if writeEvent(w, e) != nil {
+				return
+			}
+		}
+
+		keepAlive := time.NewTicker(keepAliveInterval)
+		defer keepAlive.Stop()
+
+		for {
+			select {
+			case e, ok := <-subscription.Events():
+				if !ok {
+					return
+				}
+
+				if writeEvent(w, e) != nil {
+					return
+				}
+			case <-keepAlive.C:
+				_, err := w.WriteString(": keep-alive\n\n")
+				if err != nil || w.Flush() != nil {
+					return
+				}
+			}
+		}
+	})
+}
+
+// writeEvent writes e as a server-sent event named after its type.
+func writeEvent(w *bufio.Writer, e event.TaskEvent) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		logger.Error("Error encoding task event").WithUint64("task_id", e.TaskID).WithError(err).Log()
+		return nil
+	}
+
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", e.Type, data)
+	if err != nil {
+		return err
+	}
+
+	return w.Flush()
+}
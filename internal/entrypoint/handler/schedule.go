@@ -0,0 +1,151 @@
+package handler
+
+import (
+	_ "task-pool/internal/domain/entity" // for swagger docs
+	"task-pool/internal/service/contracts"
+	"task-pool/pkg/apperror"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+type ScheduleHandler struct {
+	scheduleService contracts.ScheduleService
+}
+
+func NewScheduleHandler(scheduleService contracts.ScheduleService) *ScheduleHandler {
+	return &ScheduleHandler{scheduleService: scheduleService}
+}
+
+// CreateSchedule creates a new recurring schedule
+//
+//	@Summary		Create a new schedule
+//	@Description	Create a cron schedule that creates a task from its template on every tick
+//	@Tags			schedules
+//	@Accept			json
+//	@Produce		json
+//	@Param			schedule	body		contracts.CreateSchedule	true	"Schedule creation request"
+//	@Success		201			{object}	entity.Schedule				"Created schedule"
+//	@Failure		400			{object}	map[string]string			"Bad request - invalid input"
+//	@Failure		500			{object}	map[string]string			"Internal server error"
+//	@Router			/api/v1/schedules [post]
+func (h *ScheduleHandler) CreateSchedule(c fiber.Ctx) error {
+	var command contracts.CreateSchedule
+	if err := c.Bind().Body(&command); err != nil {
+		return apperror.HandleError(c, bindError(err))
+	}
+
+	schedule, err := h.scheduleService.Create(c.Context(), &command)
+	if err != nil {
+		return apperror.HandleError(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(schedule)
+}
+
+// GetScheduleByID retrieves a schedule by its ID
+//
+//	@Summary		Get schedule by ID
+//	@Description	Get a specific schedule by its unique identifier
+//	@Tags			schedules
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		uint64				true	"Schedule ID"
+//	@Success		200	{object}	entity.Schedule		"Schedule details"
+//	@Failure		400	{object}	map[string]string	"Bad request - invalid ID format"
+//	@Failure		404	{object}	map[string]string	"Schedule not found"
+//	@Failure		500	{object}	map[string]string	"Internal server error"
+//	@Router			/api/v1/schedules/{id} [get]
+func (h *ScheduleHandler) GetScheduleByID(c fiber.Ctx) error {
+	id, err := parseID(c)
+	if err != nil {
+		return apperror.HandleError(c, err)
+	}
+
+	schedule, err := h.scheduleService.GetByID(c.Context(), id)
+	if err != nil {
+		return apperror.HandleError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(schedule)
+}
+
+// GetAllSchedules retrieves all schedules
+//
+//	@Summary		Get all schedules
+//	@Description	Get a list of all schedules in the system
+//	@Tags			schedules
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{array}		entity.Schedule		"List of schedules"
+//	@Failure		500	{object}	map[string]string	"Internal server error"
+//	@Router			/api/v1/schedules [get]
+func (h *ScheduleHandler) GetAllSchedules(c fiber.Ctx) error {
+	schedules, err := h.scheduleService.GetAll(c.Context())
+	if err != nil {
+		return apperror.HandleError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(schedules)
+}
+
+// UpdateSchedule replaces a schedule
+//
+//	@Summary		Update a schedule
+//	@Description	Replace the cron expression, task template and enabled state of a schedule
+//	@Tags			schedules
+//	@Accept			json
+//	@Produce		json
+//	@Param			id			path		uint64						true	"Schedule ID"
+//	@Param			schedule	body		contracts.UpdateSchedule	true	"Schedule update request"
+//	@Success		200			{object}	entity.Schedule				"Updated schedule"
+//	@Failure		400			{object}	map[string]string			"Bad request - invalid input"
+//	@Failure		404			{object}	map[string]string			"Schedule not found"
+//	@Failure		500			{object}	map[string]string			"Internal server error"
+//	@Router			/api/v1/schedules/{id} [put]
+func (h *ScheduleHandler) UpdateSchedule(c fiber.Ctx) error {
+	id, err := parseID(c)
+	if err != nil {
+		return apperror.HandleError(c, err)
+	}
+
+	var command contracts.UpdateSchedule
+	if err := c.Bind().Body(&command); err != nil {
+		return apperror.HandleError(c, bindError(err))
+	}
+
+	schedule, err := h.scheduleService.Update(c.Context(), id, &command)
+	if err != nil {
+		return apperror.HandleError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(schedule)
+}
+
+// DeleteSchedule deletes a schedule
+//
+//	@Summary		Delete a schedule
+//	@Description	Delete a schedule, tasks it already created are kept
+//	@Tags			schedules
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		uint64				true	"Schedule ID"
+//	@Success		200	{object}	map[string]string	"Schedule deleted successfully"
+//	@Failure		400	{object}	map[string]string	"Bad request - invalid ID format"
+//	@Failure		404	{object}	map[string]string	"Schedule not found"
+//	@Failure		500	{object}	map[string]string	"Internal server error"
+//	@Router			/api/v1/schedules/{id} [delete]
+func (h *ScheduleHandler) DeleteSchedule(c fiber.Ctx) error {
+	id, err := parseID(c)
+	if err != nil {
+		return apperror.HandleError(c, err)
+	}
+
+	err = h.scheduleService.Delete(c.Context(), id)
+	if err != nil {
+		return apperror.HandleError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Schedule deleted successfully",
+	})
+}
@@ -1,7 +1,9 @@
 package handler
 
 import (
+	"errors"
 	"strconv"
+	"strings"
 	_ "task-pool/internal/domain/entity" // for swagger docs
 	"task-pool/internal/service/contracts"
 	"task-pool/pkg/apperror"
@@ -20,28 +22,71 @@ func NewTaskHandler(taskService contracts.TaskService) *TaskHandler {
 // CreateTask creates a new task
 //
 //	@Summary		Create a new task
-//	@Description	Create a new task with title and description
+//	@Description	Create a new task and return it, the Location header points to the task. A request repeated with the same idempotency key returns the original task instead of creating another one
 //	@Tags			tasks
 //	@Accept			json
 //	@Produce		json
-//	@Param			task	body		contracts.CreateTask	true	"Task creation request"
-//	@Success		201		{object}	map[string]string		"Task created successfully"
-//	@Failure		400		{object}	map[string]string		"Bad request - invalid input"
-//	@Failure		500		{object}	map[string]string		"Internal server error"
+//	@Param			Idempotency-Key	header		string					false	"Idempotency key, overrides idempotency_key of the body"
+//	@Param			task			body		contracts.CreateTask	true	"Task creation request"
+//	@Success		200				{object}	entity.Task				"Original task of a repeated request"
+//	@Success		201				{object}	entity.Task				"Created task"
+//	@Header			200,201			{string}	Location				"URL of the task"
+//	@Failure		400				{object}	map[string]string		"Bad request - invalid input"
+//	@Failure		429				{object}	map[string]string		"Queue is full, retry after the Retry-After header"
+//	@Failure		500				{object}	map[string]string		"Internal server error"
+//	@Failure		503				{object}	map[string]string		"Queue stayed full for the overflow timeout"
 //	@Router			/api/v1/tasks [post]
 func (h *TaskHandler) CreateTask(c fiber.Ctx) error {
 	var command contracts.CreateTask
 	if err := c.Bind().Body(&command); err != nil {
+		return apperror.HandleError(c, bindError(err))
+	}
+
+	if key := c.Get("Idempotency-Key"); key != "" {
+		command.IdempotencyKey = key
+	}
+
+	task, created, err := h.taskService.Create(c.Context(), &command)
+	if err != nil {
 		return apperror.HandleError(c, err)
 	}
 
-	err := h.taskService.Create(c.Context(), &command)
+	c.Location(strings.TrimSuffix(c.Path(), "/") + "/" + strconv.FormatUint(task.ID, 10))
+
+	if !created {
+		return c.Status(fiber.StatusOK).JSON(task)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(task)
+}
+
+// CreateTasks creates many tasks at once
+//
+//	@Summary		Create tasks in bulk
+//	@Description	Create up to 1000 tasks in one transaction and return their IDs in request order. When any task is invalid nothing is created and the offending tasks are reported by index
+//	@Tags			tasks
+//	@Accept			json
+//	@Produce		json
+//	@Param			tasks	body		contracts.CreateTasks	true	"Tasks to create"
+//	@Success		201		{object}	map[string][]uint64		"IDs of the created tasks"
+//	@Failure		400		{object}	map[string]string		"Bad request - invalid input"
+//	@Failure		429		{object}	map[string]string		"Queue is full, retry after the Retry-After header"
+//	@Failure		500		{object}	map[string]string		"Internal server error"
+//	@Failure		503		{object}	map[string]string		"Queue stayed full for the overflow timeout"
+//	@Router			/api/v1/tasks/batch [post]
+func (h *TaskHandler) CreateTasks(c fiber.Ctx) error {
+	var command contracts.CreateTasks
+	if err := c.Bind().Body(&command); err != nil {
+		return apperror.HandleError(c, bindError(err))
+	}
+
+	ids, err := h.taskService.CreateBatch(c.Context(), &command)
 	if err != nil {
 		return apperror.HandleError(c, err)
 	}
 
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
-		"message": "Task created successfully",
+		"ids": ids,
 	})
 }
 
@@ -59,8 +104,7 @@ func (h *TaskHandler) CreateTask(c fiber.Ctx) error {
 //	@Failure		500	{object}	map[string]string	"Internal server error"
 //	@Router			/api/v1/tasks/{id} [get]
 func (h *TaskHandler) GetTaskByID(c fiber.Ctx) error {
-	idStr := c.Params("id")
-	id, err := strconv.ParseUint(idStr, 10, 64)
+	id, err := parseID(c)
 	if err != nil {
 		return apperror.HandleError(c, err)
 	}
@@ -73,21 +117,237 @@ func (h *TaskHandler) GetTaskByID(c fiber.Ctx) error {
 	return c.Status(fiber.StatusOK).JSON(task)
 }
 
-// GetAllTasks retrieves all tasks
+// GetAllTasks retrieves a page of tasks
 //
 //	@Summary		Get all tasks
-//	@Description	Get a list of all tasks in the system
+//	@Description	Get a page of tasks, optionally filtered and sorted. Pass next_cursor of a page as cursor to get the following one
 //	@Tags			tasks
 //	@Accept			json
 //	@Produce		json
-//	@Success		200	{array}		entity.Task			"List of tasks"
-//	@Failure		500	{object}	map[string]string	"Internal server error"
+//	@Param			status			query		string				false	"Filter by status"
+//	@Param			type			query		string				false	"Filter by type"
+//	@Param			queue			query		string				false	"Filter by queue"
+//	@Param			created_after	query		string				false	"Created at or after, RFC 3339"
+//	@Param			created_before	query		string				false	"Created before, RFC 3339"
+//	@Param			sort			query		string				false	"id, created_at, updated_at or priority, prefix with - for descending order"	default(-created_at)
+//	@Param			cursor			query		string				false	"next_cursor of the previous page"
+//	@Param			limit			query		int					false	"Page size, at most 100"	default(20)
+//	@Success		200				{object}	contracts.TaskPage	"Page of tasks"
+//	@Failure		400				{object}	map[string]string	"Bad request - invalid query"
+//	@Failure		500				{object}	map[string]string	"Internal server error"
 //	@Router			/api/v1/tasks [get]
 func (h *TaskHandler) GetAllTasks(c fiber.Ctx) error {
-	tasks, err := h.taskService.GetAll(c.Context())
+	var query contracts.ListTasks
+	if err := c.Bind().Query(&query); err != nil {
+		return apperror.HandleError(c, bindError(err))
+	}
+
+	page, err := h.taskService.GetAll(c.Context(), &query)
+	if err != nil {
+		return apperror.HandleError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(page)
+}
+
+// GetDeadLetteredTasks retrieves the dead-letter queue
+//
+//	@Summary		Get dead-lettered tasks
+//	@Description	Get the tasks the worker gave up on after exhausting their retries
+//	@Tags			tasks
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{array}		entity.Task			"List of dead-lettered tasks"
+//	@Failure		500	{object}	map[string]string	"Internal server error"
+//	@Router			/api/v1/tasks/dead [get]
+func (h *TaskHandler) GetDeadLetteredTasks(c fiber.Ctx) error {
+	tasks, err := h.taskService.GetDeadLettered(c.Context())
 	if err != nil {
 		return apperror.HandleError(c, err)
 	}
 
 	return c.Status(fiber.StatusOK).JSON(tasks)
 }
+
+// GetTaskGraph retrieves the dependency graph of a task
+//
+//	@Summary		Get task dependency graph
+//	@Description	Get every task connected to the task through dependencies, with an edge from each dependency to the task waiting for it
+//	@Tags			tasks
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		uint64				true	"Task ID"
+//	@Success		200	{object}	contracts.TaskGraph	"Dependency graph"
+//	@Failure		400	{object}	map[string]string	"Bad request - invalid ID format"
+//	@Failure		404	{object}	map[string]string	"Task not found"
+//	@Failure		500	{object}	map[string]string	"Internal server error"
+//	@Router			/api/v1/tasks/{id}/graph [get]
+func (h *TaskHandler) GetTaskGraph(c fiber.Ctx) error {
+	id, err := parseID(c)
+	if err != nil {
+		return apperror.HandleError(c, err)
+	}
+
+	graph, err := h.taskService.GetGraph(c.Context(), id)
+	if err != nil {
+		return apperror.HandleError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(graph)
+}
+
+// GetTaskAttempts retrieves the execution history of a task
+//
+//	@Summary		Get task attempts
+//	@Description	Get every execution of a task with its worker, timing, outcome and error, oldest first
+//	@Tags			tasks
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		uint64				true	"Task ID"
+//	@Success		200	{array}		entity.TaskAttempt	"List of attempts"
+//	@Failure		400	{object}	map[string]string	"Bad request - invalid ID format"
+//	@Failure		404	{object}	map[string]string	"Task not found"
+//	@Failure		500	{object}	map[string]string	"Internal server error"
+//	@Router			/api/v1/tasks/{id}/attempts [get]
+func (h *TaskHandler) GetTaskAttempts(c fiber.Ctx) error {
+	id, err := parseID(c)
+	if err != nil {
+		return apperror.HandleError(c, err)
+	}
+
+	attempts, err := h.taskService.GetAttempts(c.Context(), id)
+	if err != nil {
+		return apperror.HandleError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(attempts)
+}
+
+// CancelTask stops a pending or running task
+//
+//	@Summary		Cancel a task
+//	@Description	Cancel a pending task so it never starts, or abort a running one through its handler context
+//	@Tags			tasks
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		uint64				true	"Task ID"
+//	@Success		200	{object}	map[string]string	"Task cancelled successfully"
+//	@Failure		400	{object}	map[string]string	"Bad request - task already finished"
+//	@Failure		404	{object}	map[string]string	"Task not found"
+//	@Failure		500	{object}	map[string]string	"Internal server error"
+//	@Router			/api/v1/tasks/{id}/cancel [post]
+func (h *TaskHandler) CancelTask(c fiber.Ctx) error {
+	id, err := parseID(c)
+	if err != nil {
+		return apperror.HandleError(c, err)
+	}
+
+	err = h.taskService.Cancel(c.Context(), id)
+	if err != nil {
+		return apperror.HandleError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Task cancelled successfully",
+	})
+}
+
+// RequeueTask moves a dead-lettered task back to the queue
+//
+//	@Summary		Requeue a dead-lettered task
+//	@Description	Move a dead-lettered task back to pending with its attempts reset
+//	@Tags			tasks
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		uint64				true	"Task ID"
+//	@Success		200	{object}	map[string]string	"Task requeued successfully"
+//	@Failure		400	{object}	map[string]string	"Bad request - task is not dead-lettered"
+//	@Failure		404	{object}	map[string]string	"Task not found"
+//	@Failure		500	{object}	map[string]string	"Internal server error"
+//	@Router			/api/v1/tasks/{id}/requeue [post]
+func (h *TaskHandler) RequeueTask(c fiber.Ctx) error {
+	id, err := parseID(c)
+	if err != nil {
+		return apperror.HandleError(c, err)
+	}
+
+	err = h.taskService.Requeue(c.Context(), id)
+	if err != nil {
+		return apperror.HandleError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Task requeued successfully",
+	})
+}
+
+// RequeueDeadLetteredTasks moves dead-lettered tasks back to the queue in bulk
+//
+//	@Summary		Requeue dead-lettered tasks
+//	@Description	Move the given dead-lettered tasks, or all of them when no ids are sent, back to pending
+//	@Tags			tasks
+//	@Accept			json
+//	@Produce		json
+//	@Param			tasks	body		contracts.RequeueTasks	false	"Tasks to requeue"
+//	@Success		200		{object}	map[string]int64		"Number of requeued tasks"
+//	@Failure		400		{object}	map[string]string		"Bad request - invalid input"
+//	@Failure		500		{object}	map[string]string		"Internal server error"
+//	@Router			/api/v1/tasks/dead/requeue [post]
+func (h *TaskHandler) RequeueDeadLetteredTasks(c fiber.Ctx) error {
+	var command contracts.RequeueTasks
+	if len(c.Body()) > 0 {
+		if err := c.Bind().Body(&command); err != nil {
+			return apperror.HandleError(c, bindError(err))
+		}
+	}
+
+	count, err := h.taskService.RequeueDeadLettered(c.Context(), &command)
+	if err != nil {
+		return apperror.HandleError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"requeued": count,
+	})
+}
+
+// PurgeDeadLetteredTasks deletes the dead-letter queue
+//
+//	@Summary		Purge dead-lettered tasks
+//	@Description	Delete every dead-lettered task
+//	@Tags			tasks
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	map[string]int64	"Number of purged tasks"
+//	@Failure		500	{object}	map[string]string	"Internal server error"
+//	@Router			/api/v1/tasks/dead [delete]
+func (h *TaskHandler) PurgeDeadLetteredTasks(c fiber.Ctx) error {
+	count, err := h.taskService.PurgeDeadLettered(c.Context())
+	if err != nil {
+		return apperror.HandleError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"purged": count,
+	})
+}
+
+// bindError turns a request that cannot be decoded into a bad request,
+// validation failures already are one.
+func bindError(err error) error {
+	var appErr *apperror.AppError
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+
+	return apperror.BadRequest("invalid request").Wrap(err)
+}
+
+func parseID(c fiber.Ctx) (uint64, error) {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return 0, apperror.BadRequest("invalid id").Wrap(err)
+	}
+
+	return id, nil
+}
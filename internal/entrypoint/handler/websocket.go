@@ -0,0 +1,282 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+	"sync"
+	"task-pool/config"
+	"task-pool/internal/domain/entity"
+	"task-pool/internal/event"
+	"task-pool/pkg/apperror"
+	"task-pool/pkg/logger"
+	"time"
+
+	"github.com/fasthttp/websocket"
+	"github.com/gofiber/fiber/v3"
+	"github.com/valyala/fasthttp"
+)
+
+const (
+	// wsWriteWait bounds the time a single frame may take to be written.
+	wsWriteWait = 10 * time.Second
+	// wsMaxMessageSize bounds the requests a client sends.
+	wsMaxMessageSize = 64 * 1024
+)
+
+// Actions a WebSocket client sends.
+const (
+	wsActionSubscribe   = "subscribe"
+	wsActionUnsubscribe = "unsubscribe"
+	wsActionPing        = "ping"
+)
+
+// wsRequest is a message sent by a WebSocket client.
+type wsRequest struct {
+	Action   string              `json:"action"`
+	TaskIDs  []uint64            `json:"task_ids"`
+	Queues   []string            `json:"queues"`
+	Statuses []entity.TaskStatus `json:"statuses"`
+}
+
+// Types of the replies to the requests of a WebSocket client.
+const (
+	wsReplySubscribed   = "subscribed"
+	wsReplyUnsubscribed = "unsubscribed"
+	wsReplyPong         = "pong"
+	wsReplyError        = "error"
+)
+
+// wsReply answers a wsRequest with the number of subscriptions left.
+type wsReply struct {
+	Type          string `json:"type"`
+	Subscriptions int    `json:"subscriptions"`
+	Error         string `json:"error,omitempty"`
+}
+
+type WebSocketHandler struct {
+	bus              *event.Bus
+	upgrader         websocket.FastHTTPUpgrader
+	pingInterval     time.Duration
+	maxSubscriptions int
+	allowedOrigins   []string
+}
+
+func NewWebSocketHandler(bus *event.Bus, cfg config.Server) *WebSocketHandler {
+	h := &WebSocketHandler{
+		bus:              bus,
+		pingInterval:     cfg.WebSocketPingInterval,
+		maxSubscriptions: cfg.WebSocketMaxSubscriptions,
+		allowedOrigins:   cfg.WebSocketAllowedOrigins,
+	}
+	h.upgrader.CheckOrigin = h.checkOrigin
+
+	return h
+}
+
+// checkOrigin accepts the upgrades without an Origin header, e.g. from
+// non-browser clients, and the ones from the server's own host or from one of
+// the allowed origins.
+func (h *WebSocketHandler) checkOrigin(ctx *fasthttp.RequestCtx) bool {
+	origin := string(ctx.Request.Header.Peek(fiber.HeaderOrigin))
+	if origin == "" {
+		return true
+	}
+
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+
+	if strings.EqualFold(u.Host, string(ctx.Host())) {
+		return true
+	}
+
+	for _, allowed := range h.allowedOrigins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Subscribe upgrades the request to a WebSocket connection that receives the
+// events of the tasks, queues and statuses the client subscribes to.
+//
+//	@Summary		Subscribe to task events over WebSocket
+//	@Description	Upgrade to a WebSocket connection. The client sends {"action": "subscribe" | "unsubscribe" | "ping", "task_ids": [], "queues": [], "statuses": []} messages and receives the matching task events as JSON frames
+//	@Tags			events
+//	@Success		101	{object}	event.TaskEvent		"Switching protocols, frames are task events"
+//	@Failure		400	{object}	map[string]string	"Bad request - not a WebSocket upgrade"
+//	@Router			/ws [get]
+func (h *WebSocketHandler) Subscribe(c fiber.Ctx) error {
+	if !websocket.FastHTTPIsWebSocketUpgrade(c.RequestCtx()) {
+		return apperror.HandleError(c, apperror.BadRequest("websocket upgrade required"))
+	}
+
+	// A failed upgrade has already been answered by the upgrader.
+	_ = h.upgrader.Upgrade(c.RequestCtx(), h.serve)
+
+	return nil
+}
+
+// wsConn serializes the writes to a connection, the events and the replies
+// to the client are written from different goroutines.
+type wsConn struct {
+	*websocket.Conn
+	mu sync.Mutex
+}
+
+func (c *wsConn) writeJSON(v any) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	err := c.SetWriteDeadline(time.Now().Add(wsWriteWait))
+	if err != nil {
+		return err
+	}
+
+	return c.WriteJSON(v)
+}
+
+// wsSubscriptions is the filter of a connection, it is updated by the
+// requests of the client while events are matched against it.
+type wsSubscriptions struct {
+	mu     sync.Mutex
+	filter *event.Filter
+}
+
+// handle applies request to the filter, a nil request is one that could not
+// be decoded.
+func (s *wsSubscriptions) handle(request *wsRequest) wsReply {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if request == nil {
+		return wsReply{Type: wsReplyError, Subscriptions: s.filter.Len(), Error: "invalid request"}
+	}
+
+	var reply wsReply
+
+	switch request.Action {
+	case wsActionSubscribe:
+		reply.Type = wsReplySubscribed
+
+		err := s.filter.Add(request.TaskIDs, request.Queues, request.Statuses)
+		if err != nil {
+			reply = wsReply{Type: wsReplyError, Error: err.Error()}
+		}
+	case wsActionUnsubscribe:
+		reply.Type = wsReplyUnsubscribed
+		s.filter.Remove(request.TaskIDs, request.Queues, request.Statuses)
+	case wsActionPing:
+		reply.Type = wsReplyPong
+	default:
+		reply = wsReply{Type: wsReplyError, Error: "unknown action"}
+	}
+
+	reply.Subscriptions = s.filter.Len()
+
+	return reply
+}
+
+func (s *wsSubscriptions) match(e event.TaskEvent) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.filter.Match(e)
+}
+
+// serve runs a connection: requests are read on their own goroutine while
+// events and pings are written on this one. The connection is closed as soon
+// as either side ends.
+func (h *WebSocketHandler) serve(c *websocket.Conn) {
+	conn := &wsConn{Conn: c}
+	subscription := h.bus.Subscribe(0)
+	defer subscription.Close()
+
+	subscriptions := &wsSubscriptions{filter: event.NewFilter(h.maxSubscriptions)}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		h.read(conn, subscriptions)
+	}()
+
+	h.write(conn, subscription, subscriptions, done)
+
+	conn.Close()
+	<-done
+}
+
+// read handles the requests of the client until the connection fails or the
+// client stops answering pings.
+func (h *WebSocketHandler) read(conn *wsConn, subscriptions *wsSubscriptions) {
+	pongWait := 2 * h.pingInterval
+
+	conn.SetReadLimit(wsMaxMessageSize)
+	_ = conn.SetReadDeadline(time.Now().Add(pongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	for {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway, websocket.CloseTryAgainLater) {
+				logger.Warn("WebSocket connection lost").WithError(err).Log()
+			}
+			return
+		}
+
+		request := &wsRequest{}
+		if json.Unmarshal(message, request) != nil {
+			request = nil
+		}
+
+		if conn.writeJSON(subscriptions.handle(request)) != nil {
+			return
+		}
+	}
+}
+
+// write sends the events matching filter and pings the client every ping
+// interval. A client falling behind the bus, or connected while the bus is
+// closed on shutdown, is told to reconnect.
+func (h *WebSocketHandler) write(
+	conn *wsConn,
+	subscription *event.Subscription,
+	subscriptions *wsSubscriptions,
+	done <-chan struct{},
+) {
+	ping := time.NewTicker(h.pingInterval)
+	defer ping.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case e, ok := <-subscription.Events():
+			if !ok {
+				message := websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "event stream ended")
+				_ = conn.WriteControl(websocket.CloseMessage, message, time.Now().Add(wsWriteWait))
+
+				// Give the client a chance to answer the close frame.
+				select {
+				case <-done:
+				case <-time.After(wsWriteWait):
+				}
+				return
+			}
+
+			if subscriptions.match(e) && conn.writeJSON(e) != nil {
+				return
+			}
+		case <-ping.C:
+			if conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteWait)) != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"strconv"
+	"strings"
+	"task-pool/internal/service/contracts"
+	"task-pool/pkg/apperror"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+type WorkflowHandler struct {
+	workflowService contracts.WorkflowService
+}
+
+func NewWorkflowHandler(workflowService contracts.WorkflowService) *WorkflowHandler {
+	return &WorkflowHandler{workflowService: workflowService}
+}
+
+// CreateWorkflow creates a new workflow
+//
+//	@Summary		Create a new workflow
+//	@Description	Create a workflow whose steps run one after another, the tasks of a step run in parallel and receive the results of the previous step as input. The Location header points to the workflow
+//	@Tags			workflows
+//	@Accept			json
+//	@Produce		json
+//	@Param			workflow	body		contracts.CreateWorkflow	true	"Workflow creation request"
+//	@Success		201			{object}	contracts.Workflow			"Created workflow"
+//	@Header			201			{string}	Location					"URL of the workflow"
+//	@Failure		400			{object}	map[string]string			"Bad request - invalid input"
+//	@Failure		429			{object}	map[string]string			"Queue is full, retry after the Retry-After header"
+//	@Failure		500			{object}	map[string]string			"Internal server error"
+//	@Failure		503			{object}	map[string]string			"Queue stayed full for the overflow timeout"
+//	@Router			/api/v1/workflows [post]
+func (h *WorkflowHandler) CreateWorkflow(c fiber.Ctx) error {
+	var command contracts.CreateWorkflow
+	if err := c.Bind().Body(&command); err != nil {
+		return apperror.HandleError(c, bindError(err))
+	}
+
+	workflow, err := h.workflowService.Create(c.Context(), &command)
+	if err != nil {
+		return apperror.HandleError(c, err)
+	}
+
+	c.Location(strings.TrimSuffix(c.Path(), "/") + "/" + strconv.FormatUint(workflow.ID, 10))
+
+	return c.Status(fiber.StatusCreated).JSON(workflow)
+}
+
+// GetWorkflowByID retrieves a workflow by its ID
+//
+//	@Summary		Get workflow by ID
+//	@Description	Get a workflow with its tasks grouped by step, its status and progress are derived from the tasks
+//	@Tags			workflows
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		uint64				true	"Workflow ID"
+//	@Success		200	{object}	contracts.Workflow	"Workflow details"
+//	@Failure		400	{object}	map[string]string	"Bad request - invalid ID format"
+//	@Failure		404	{object}	map[string]string	"Workflow not found"
+//	@Failure		500	{object}	map[string]string	"Internal server error"
+//	@Router			/api/v1/workflows/{id} [get]
+func (h *WorkflowHandler) GetWorkflowByID(c fiber.Ctx) error {
+	id, err := parseID(c)
+	if err != nil {
+		return apperror.HandleError(c, err)
+	}
+
+	workflow, err := h.workflowService.GetByID(c.Context(), id)
+	if err != nil {
+		return apperror.HandleError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(workflow)
+}
@@ -8,7 +8,12 @@ import (
 )
 
 type HandlerOptions struct {
-	TaskHandler *handler.TaskHandler
+	TaskHandler     *handler.TaskHandler
+	ScheduleHandler *handler.ScheduleHandler
+	WorkflowHandler *handler.WorkflowHandler
+	EventHandler    *handler.EventHandler
+
+	WebSocketHandler *handler.WebSocketHandler
 }
 
 func RegisterHttpHandlers(app *fiber.App, options HandlerOptions) {
@@ -16,6 +21,8 @@ func RegisterHttpHandlers(app *fiber.App, options HandlerOptions) {
 		return c.SendString("OK")
 	})
 
+	app.Get("/ws", options.WebSocketHandler.Subscribe)
+
 	// Swagger
 	app.Get("/swagger.json", func(c fiber.Ctx) error {
 		return c.SendFile("docs/swagger.json")
@@ -26,10 +33,36 @@ func RegisterHttpHandlers(app *fiber.App, options HandlerOptions) {
 	}))
 
 	apiV1 := app.Group("/api/v1")
+	apiV1.Get("/events", options.EventHandler.Events)
+
 	taskGroup := apiV1.Group("/tasks")
 	{
 		taskGroup.Post("", options.TaskHandler.CreateTask)
+		taskGroup.Post("/batch", options.TaskHandler.CreateTasks)
 		taskGroup.Get("", options.TaskHandler.GetAllTasks)
+		taskGroup.Get("/dead", options.TaskHandler.GetDeadLetteredTasks)
+		taskGroup.Post("/dead/requeue", options.TaskHandler.RequeueDeadLetteredTasks)
+		taskGroup.Delete("/dead", options.TaskHandler.PurgeDeadLetteredTasks)
 		taskGroup.Get("/:id", options.TaskHandler.GetTaskByID)
+		taskGroup.Get("/:id/attempts", options.TaskHandler.GetTaskAttempts)
+		taskGroup.Get("/:id/graph", options.TaskHandler.GetTaskGraph)
+		taskGroup.Get("/:id/events", options.EventHandler.TaskEvents)
+		taskGroup.Post("/:id/cancel", options.TaskHandler.CancelTask)
+		taskGroup.Post("/:id/requeue", options.TaskHandler.RequeueTask)
+	}
+
+	scheduleGroup := apiV1.Group("/schedules")
+	{
+		scheduleGroup.Post("", options.ScheduleHandler.CreateSchedule)
+		scheduleGroup.Get("", options.ScheduleHandler.GetAllSchedules)
+		scheduleGroup.Get("/:id", options.ScheduleHandler.GetScheduleByID)
+		scheduleGroup.Put("/:id", options.ScheduleHandler.UpdateSchedule)
+		scheduleGroup.Delete("/:id", options.ScheduleHandler.DeleteSchedule)
+	}
+
+	workflowGroup := apiV1.Group("/workflows")
+	{
+		workflowGroup.Post("", options.WorkflowHandler.CreateWorkflow)
+		workflowGroup.Get("/:id", options.WorkflowHandler.GetWorkflowByID)
 	}
 }
@@ -0,0 +1,141 @@
+package event
+
+import (
+	"encoding/json"
+	"sync"
+	"task-pool/internal/domain/entity"
+	"time"
+)
+
+type Type string
+
+const (
+	// TypeStatus is published when a task is created or changes status.
+	TypeStatus Type = "status"
+	// TypeProgress is published when the handler of a task reports progress.
+	TypeProgress Type = "progress"
+)
+
+// TaskEvent is a change of a task.
+type TaskEvent struct {
+	Type            Type              `json:"type"`
+	TaskID          uint64            `json:"task_id"`
+	Queue           string            `json:"queue"`
+	Status          entity.TaskStatus `json:"status"`
+	Progress        int               `json:"progress"`
+	ProgressMessage string            `json:"progress_message,omitempty"`
+	Error           string            `json:"error,omitempty"`
+	Result          json.RawMessage   `json:"result,omitempty" swaggertype:"object"`
+	Time            time.Time         `json:"time"`
+}
+
+// NewTaskEvent captures the current state of task.
+func NewTaskEvent(eventType Type, task *entity.Task) TaskEvent {
+	return TaskEvent{
+		Type:            eventType,
+		TaskID:          task.ID,
+		Queue:           task.Queue,
+		Status:          task.Status,
+		Progress:        task.Progress,
+		ProgressMessage: task.ProgressMessage,
+		Error:           task.Error,
+		Result:          task.Result,
+		Time:            time.Now(),
+	}
+}
+
+// Publisher is the side of the bus the worker and the services use.
+type Publisher interface {
+	Publish(event TaskEvent)
+}
+
+// Bus fans the task events of this process out to its subscribers. Publish
+// never blocks: a subscriber that falls more than the buffer size behind is
+// dropped, and its events channel closed.
+type Bus struct {
+	buffer int
+
+	mu          sync.Mutex
+	subscribers map[*Subscription]struct{}
+	closed      bool
+}
+
+func NewBus(buffer int) *Bus {
+	return &Bus{
+		buffer:      buffer,
+		subscribers: make(map[*Subscription]struct{}),
+	}
+}
+
+// Subscription receives the events published after it was created.
+type Subscription struct {
+	bus    *Bus
+	taskID uint64
+	events chan TaskEvent
+}
+
+// Subscribe returns a subscription to the events of task taskID, or to the
+// events of all tasks when taskID is 0. It must be closed once done with.
+func (b *Bus) Subscribe(taskID uint64) *Subscription {
+	s := &Subscription{bus: b, taskID: taskID, events: make(chan TaskEvent, b.buffer)}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		close(s.events)
+		return s
+	}
+
+	b.subscribers[s] = struct{}{}
+
+	return s
+}
+
+func (b *Bus) Publish(event TaskEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for s := range b.subscribers {
+		if s.taskID != 0 && s.taskID != event.TaskID {
+			continue
+		}
+
+		select {
+		case s.events <- event:
+		default:
+			b.remove(s)
+		}
+	}
+}
+
+// Close ends every subscription, it lets streaming clients go on shutdown.
+func (b *Bus) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	for s := range b.subscribers {
+		b.remove(s)
+	}
+}
+
+func (b *Bus) remove(s *Subscription) {
+	if _, ok := b.subscribers[s]; ok {
+		delete(b.subscribers, s)
+		close(s.events)
+	}
+}
+
+// Events delivers the events of the subscription. It is closed when the
+// subscription is closed, falls behind or the bus is closed.
+func (s *Subscription) Events() <-chan TaskEvent {
+	return s.events
+}
+
+func (s *Subscription) Close() {
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+
+	s.bus.remove(s)
+}
@@ -0,0 +1,86 @@
+package event
+
+import (
+	"task-pool/internal/domain/entity"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBus(t *testing.T) {
+	task := &entity.Task{ID: 1, Queue: entity.DefaultQueue, Status: entity.TaskStatusRunning}
+
+	t.Run("subscribers receive the events of their task", func(t *testing.T) {
+		bus := NewBus(10)
+		one := bus.Subscribe(1)
+		two := bus.Subscribe(2)
+		all := bus.Subscribe(0)
+
+		bus.Publish(NewTaskEvent(TypeStatus, task))
+		bus.Publish(NewTaskEvent(TypeStatus, &entity.Task{ID: 2}))
+
+		require.Len(t, one.Events(), 1)
+		assert.Equal(t, uint64(1), (<-one.Events()).TaskID)
+		require.Len(t, two.Events(), 1)
+		assert.Equal(t, uint64(2), (<-two.Events()).TaskID)
+		assert.Len(t, all.Events(), 2)
+	})
+
+	t.Run("event captures the task", func(t *testing.T) {
+		task := &entity.Task{ID: 1, Status: entity.TaskStatusCompleted, Progress: 100, Result: []byte(`{"ok":true}`)}
+
+		e := NewTaskEvent(TypeStatus, task)
+		task.Status = entity.TaskStatusPending
+
+		assert.Equal(t, entity.TaskStatusCompleted, e.Status)
+		assert.Equal(t, 100, e.Progress)
+		assert.JSONEq(t, `{"ok":true}`, string(e.Result))
+		assert.False(t, e.Time.IsZero())
+	})
+
+	t.Run("subscriber falling behind is dropped", func(t *testing.T) {
+		bus := NewBus(1)
+		slow := bus.Subscribe(0)
+		fast := bus.Subscribe(0)
+
+		bus.Publish(NewTaskEvent(TypeStatus, task))
+		<-fast.Events()
+		bus.Publish(NewTaskEvent(TypeProgress, task))
+
+		_, ok := <-slow.Events()
+		assert.True(t, ok)
+		_, ok = <-slow.Events()
+		assert.False(t, ok)
+
+		e, ok := <-fast.Events()
+		assert.True(t, ok)
+		assert.Equal(t, TypeProgress, e.Type)
+	})
+
+	t.Run("closed subscription stops receiving", func(t *testing.T) {
+		bus := NewBus(10)
+		subscription := bus.Subscribe(0)
+
+		subscription.Close()
+		subscription.Close()
+		bus.Publish(NewTaskEvent(TypeStatus, task))
+
+		_, ok := <-subscription.Events()
+		assert.False(t, ok)
+	})
+
+	t.Run("closing the bus ends every subscription", func(t *testing.T) {
+		bus := NewBus(10)
+		before := bus.Subscribe(0)
+
+		bus.Close()
+		after := bus.Subscribe(1)
+		bus.Publish(NewTaskEvent(TypeStatus, task))
+
+		_, ok := <-before.Events()
+		assert.False(t, ok)
+		_, ok = <-after.Events()
+		assert.False(t, ok)
+	})
+}
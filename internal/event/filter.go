@@ -0,0 +1,104 @@
+package event
+
+import (
+	"errors"
+	"fmt"
+	"task-pool/internal/domain/entity"
+)
+
+var ErrFilterLimit = errors.New("subscription limit reached")
+
+// Filter selects the events of a set of tasks, queues and statuses, an event
+// matching any of them passes. Every task, queue or status counts as one
+// entry and a filter holds at most limit entries. It is not safe for
+// concurrent use.
+type Filter struct {
+	limit    int
+	taskIDs  map[uint64]struct{}
+	queues   map[string]struct{}
+	statuses map[entity.TaskStatus]struct{}
+}
+
+func NewFilter(limit int) *Filter {
+	return &Filter{
+		limit:    limit,
+		taskIDs:  make(map[uint64]struct{}),
+		queues:   make(map[string]struct{}),
+		statuses: make(map[entity.TaskStatus]struct{}),
+	}
+}
+
+// Add adds the given entries to the filter. Nothing is added when a status is
+// unknown or the entries would take the filter over its limit.
+func (f *Filter) Add(taskIDs []uint64, queues []string, statuses []entity.TaskStatus) error {
+	for _, status := range statuses {
+		if !status.IsValid() {
+			return fmt.Errorf("unknown status %q", status)
+		}
+	}
+
+	added := countNew(f.taskIDs, taskIDs) + countNew(f.queues, queues) + countNew(f.statuses, statuses)
+	if f.Len()+added > f.limit {
+		return fmt.Errorf("%w, at most %d tasks, queues and statuses", ErrFilterLimit, f.limit)
+	}
+
+	insert(f.taskIDs, taskIDs)
+	insert(f.queues, queues)
+	insert(f.statuses, statuses)
+
+	return nil
+}
+
+// Remove removes the given entries, the ones not in the filter are ignored.
+func (f *Filter) Remove(taskIDs []uint64, queues []string, statuses []entity.TaskStatus) {
+	for _, id := range taskIDs {
+		delete(f.taskIDs, id)
+	}
+
+	for _, queue := range queues {
+		delete(f.queues, queue)
+	}
+
+	for _, status := range statuses {
+		delete(f.statuses, status)
+	}
+}
+
+// Len returns the number of entries of the filter.
+func (f *Filter) Len() int {
+	return len(f.taskIDs) + len(f.queues) + len(f.statuses)
+}
+
+func (f *Filter) Match(event TaskEvent) bool {
+	_, ok := f.taskIDs[event.TaskID]
+	if ok {
+		return true
+	}
+
+	_, ok = f.queues[event.Queue]
+	if ok {
+		return true
+	}
+
+	_, ok = f.statuses[event.Status]
+
+	return ok
+}
+
+// countNew counts the distinct keys that are not in set yet.
+func countNew[K comparable](set map[K]struct{}, keys []K) int {
+	seen := make(map[K]struct{}, len(keys))
+	for _, key := range keys {
+		if _, ok := set[key]; !ok {
+			seen[key] = struct{}{}
+		}
+	}
+
+	return len(seen)
+}
+
+func insert[K comparable](set map[K]struct{}, keys []K) {
+	for _, key := range keys {
+		set[key] = struct{}{}
+	}
+}
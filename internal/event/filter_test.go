@@ -0,0 +1,57 @@
+package event
+
+import (
+	"task-pool/internal/domain/entity"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilter(t *testing.T) {
+	t.Run("matches any of its tasks, queues and statuses", func(t *testing.T) {
+		filter := NewFilter(10)
+		require.NoError(t, filter.Add([]uint64{1}, []string{"emails"}, []entity.TaskStatus{entity.TaskStatusFailed}))
+
+		assert.True(t, filter.Match(TaskEvent{TaskID: 1, Queue: entity.DefaultQueue, Status: entity.TaskStatusRunning}))
+		assert.True(t, filter.Match(TaskEvent{TaskID: 2, Queue: "emails", Status: entity.TaskStatusRunning}))
+		assert.True(t, filter.Match(TaskEvent{TaskID: 3, Queue: entity.DefaultQueue, Status: entity.TaskStatusFailed}))
+		assert.False(t, filter.Match(TaskEvent{TaskID: 4, Queue: entity.DefaultQueue, Status: entity.TaskStatusCompleted}))
+	})
+
+	t.Run("empty filter matches nothing", func(t *testing.T) {
+		assert.False(t, NewFilter(10).Match(TaskEvent{TaskID: 1}))
+	})
+
+	t.Run("limit counts distinct entries", func(t *testing.T) {
+		filter := NewFilter(3)
+		require.NoError(t, filter.Add([]uint64{1, 2, 2}, nil, nil))
+		require.NoError(t, filter.Add([]uint64{1}, []string{"emails"}, nil))
+		assert.Equal(t, 3, filter.Len())
+
+		err := filter.Add(nil, nil, []entity.TaskStatus{entity.TaskStatusFailed})
+
+		require.ErrorIs(t, err, ErrFilterLimit)
+		assert.Equal(t, 3, filter.Len())
+	})
+
+	t.Run("unknown status is rejected", func(t *testing.T) {
+		filter := NewFilter(10)
+
+		err := filter.Add([]uint64{1}, nil, []entity.TaskStatus{"done"})
+
+		require.Error(t, err)
+		assert.Zero(t, filter.Len())
+	})
+
+	t.Run("removed entries no longer match", func(t *testing.T) {
+		filter := NewFilter(2)
+		require.NoError(t, filter.Add([]uint64{1}, []string{"emails"}, nil))
+
+		filter.Remove([]uint64{1, 5}, nil, nil)
+
+		assert.False(t, filter.Match(TaskEvent{TaskID: 1}))
+		assert.True(t, filter.Match(TaskEvent{TaskID: 2, Queue: "emails"}))
+		require.NoError(t, filter.Add([]uint64{2}, nil, nil))
+	})
+}
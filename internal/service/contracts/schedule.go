@@ -0,0 +1,40 @@
+package contracts
+
+import (
+	"context"
+	"encoding/json"
+	"task-pool/internal/domain/entity"
+)
+
+type ScheduleService interface {
+	// Create creates a new recurring schedule
+	Create(ctx context.Context, schedule *CreateSchedule) (*entity.Schedule, error)
+
+	// GetByID returns a schedule by its ID
+	GetByID(ctx context.Context, id uint64) (*entity.Schedule, error)
+
+	// GetAll returns all schedules
+	GetAll(ctx context.Context) ([]*entity.Schedule, error)
+
+	// Update replaces the cron expression, template and state of a schedule
+	Update(ctx context.Context, id uint64, schedule *UpdateSchedule) (*entity.Schedule, error)
+
+	// Delete deletes a schedule, tasks it already created are kept
+	Delete(ctx context.Context, id uint64) error
+}
+
+type CreateSchedule struct {
+	Name string `json:"name" validate:"required,min=3,max=255"`
+	// Cron is a standard five field cron expression or a descriptor such as
+	// "@hourly".
+	Cron        string          `json:"cron" validate:"required" example:"*/5 * * * *"`
+	Title       string          `json:"title" validate:"required,min=3,max=255"`
+	Description string          `json:"description" validate:"required,min=3,max=255"`
+	Type        string          `json:"type" validate:"required,max=255"`
+	Payload     json.RawMessage `json:"payload" swaggertype:"object"`
+}
+
+type UpdateSchedule struct {
+	CreateSchedule
+	Enabled bool `json:"enabled"`
+}
@@ -2,21 +2,129 @@ package contracts
 
 import (
 	"context"
+	"encoding/json"
 	"task-pool/internal/domain/entity"
+	"time"
 )
 
 type TaskService interface {
-	// Create creates a new task
-	Create(ctx context.Context, task *CreateTask) error
+	// CreateBatch creates all tasks in one transaction, or none of them, and
+	// returns their IDs in request order
+	CreateBatch(ctx context.Context, command *CreateTasks) ([]uint64, error)
+
+	// Create creates a new task and returns it. A request repeated with the
+	// same idempotency key returns the original task and created is false.
+	Create(ctx context.Context, task *CreateTask) (result *entity.Task, created bool, err error)
 
 	// GetByID returns a task by its ID
 	GetByID(ctx context.Context, id uint64) (*entity.Task, error)
 
-	// GetAll returns all tasks
-	GetAll(ctx context.Context) ([]*entity.Task, error)
+	// GetAll returns a page of the tasks matching the query
+	GetAll(ctx context.Context, query *ListTasks) (*TaskPage, error)
+
+	// GetDeadLettered returns the tasks that exhausted their retries
+	GetDeadLettered(ctx context.Context) ([]*entity.Task, error)
+
+	// GetGraph returns the dependency graph the task belongs to
+	GetGraph(ctx context.Context, id uint64) (*TaskGraph, error)
+
+	// GetAttempts returns the execution history of a task, oldest first
+	GetAttempts(ctx context.Context, id uint64) ([]*entity.TaskAttempt, error)
+
+	// Cancel stops a task that has not finished yet, a running handler has
+	// its context cancelled
+	Cancel(ctx context.Context, id uint64) error
+
+	// Requeue moves a single dead-lettered task back to pending
+	Requeue(ctx context.Context, id uint64) error
+
+	// RequeueDeadLettered moves dead-lettered tasks back to pending and
+	// returns how many were requeued
+	RequeueDeadLettered(ctx context.Context, command *RequeueTasks) (int64, error)
+
+	// PurgeDeadLettered deletes all dead-lettered tasks and returns how many
+	// were deleted
+	PurgeDeadLettered(ctx context.Context) (int64, error)
 }
 
 type CreateTask struct {
 	Title       string `json:"title" validate:"required,min=3,max=255"`
 	Description string `json:"description" validate:"required,min=3,max=255"`
+	Type        string `json:"type" validate:"required,max=255"`
+	// Queue selects the worker pool of the task, "default" when empty.
+	Queue   string          `json:"queue" validate:"omitempty,max=255" example:"emails"`
+	Payload json.RawMessage `json:"payload" swaggertype:"object"`
+	// Priority ranges from 0 to 10, higher priorities are processed first.
+	Priority int `json:"priority" validate:"min=0,max=10"`
+	// MaxAttempts overrides TASK_WORKER_MAX_ATTEMPTS for this task when set.
+	MaxAttempts int `json:"max_attempts" validate:"omitempty,min=1,max=100"`
+	// RunAt and Delay postpone the first run of the task, at most one of
+	// them may be set. Delay is a duration such as "90s" or "1h30m".
+	RunAt *time.Time `json:"run_at"`
+	Delay string     `json:"delay" example:"5m"`
+	// Timeout overrides TASK_WORKER_TIMEOUT for this task, e.g. "30s".
+	Timeout string `json:"timeout" example:"30s"`
+	// IdempotencyKey makes retries of the request safe, the Idempotency-Key
+	// header takes precedence over it.
+	IdempotencyKey string `json:"idempotency_key" validate:"omitempty,max=255" example:"order-1234-receipt"`
+	// DependsOn lists the tasks that must complete before this one runs, the
+	// task is blocked until then and cancelled if one of them does not
+	// complete.
+	DependsOn []uint64 `json:"depends_on" validate:"max=100,dive,min=1" example:"1,2"`
+}
+
+type CreateTasks struct {
+	// Tasks holds at most 1000 tasks, idempotency keys and dependencies are
+	// not supported.
+	Tasks []CreateTask `json:"tasks" validate:"required,min=1,max=1000,dive"`
+}
+
+type ListTasks struct {
+	Status string `query:"status" example:"failed"`
+	Type   string `query:"type" example:"sleep"`
+	Queue  string `query:"queue" example:"emails"`
+	// CreatedAfter and CreatedBefore bound the creation time in RFC 3339,
+	// CreatedAfter is inclusive and CreatedBefore exclusive.
+	CreatedAfter  string `query:"created_after" example:"2024-01-01T00:00:00Z"`
+	CreatedBefore string `query:"created_before" example:"2024-02-01T00:00:00Z"`
+	// Sort is id, created_at, updated_at or priority, a "-" prefix sorts in
+	// descending order. Defaults to "-created_at".
+	Sort string `query:"sort" example:"-created_at"`
+	// Cursor is the next_cursor of the previous page.
+	Cursor string `query:"cursor"`
+	// Limit is the page size, 20 by default and at most 100.
+	Limit int `query:"limit" example:"20"`
+}
+
+type TaskPage struct {
+	Items []*entity.Task `json:"items"`
+	// NextCursor fetches the following page, it is empty on the last page.
+	NextCursor string `json:"next_cursor"`
+	// Total counts the tasks matching the query across all pages.
+	Total int64 `json:"total"`
+}
+
+type TaskGraph struct {
+	Nodes []TaskGraphNode `json:"nodes"`
+	Edges []TaskGraphEdge `json:"edges"`
+}
+
+type TaskGraphNode struct {
+	ID     uint64            `json:"id"`
+	Title  string            `json:"title"`
+	Type   string            `json:"type"`
+	Queue  string            `json:"queue"`
+	Status entity.TaskStatus `json:"status"`
+}
+
+// TaskGraphEdge means task From must complete before task To runs.
+type TaskGraphEdge struct {
+	From uint64 `json:"from"`
+	To   uint64 `json:"to"`
+}
+
+type RequeueTasks struct {
+	// IDs limits the requeue to the given tasks, all dead-lettered tasks are
+	// requeued when empty.
+	IDs []uint64 `json:"ids"`
 }
@@ -0,0 +1,41 @@
+package contracts
+
+import (
+	"context"
+	"task-pool/internal/domain/entity"
+	"time"
+)
+
+type WorkflowService interface {
+	// Create creates a workflow and the tasks of all its steps
+	Create(ctx context.Context, workflow *CreateWorkflow) (*Workflow, error)
+
+	// GetByID returns a workflow with the status derived from its tasks
+	GetByID(ctx context.Context, id uint64) (*Workflow, error)
+}
+
+type CreateWorkflow struct {
+	Name string `json:"name" validate:"required,min=3,max=255"`
+	// Steps run one after another, a step starts once every task of the
+	// previous step has completed. Idempotency keys and dependencies of the
+	// tasks are not supported.
+	Steps []WorkflowStep `json:"steps" validate:"required,min=1,max=100,dive"`
+}
+
+// WorkflowStep holds the tasks that run in parallel, several tasks fan out
+// the workflow and the next step fans it back in.
+type WorkflowStep struct {
+	Tasks []CreateTask `json:"tasks" validate:"required,min=1,max=100,dive"`
+}
+
+type Workflow struct {
+	ID     uint64                `json:"id"`
+	Name   string                `json:"name"`
+	Status entity.WorkflowStatus `json:"status"`
+	// Progress is the percentage of completed tasks.
+	Progress  int              `json:"progress" example:"50"`
+	Completed int              `json:"completed"`
+	Total     int              `json:"total"`
+	Steps     [][]*entity.Task `json:"steps"`
+	CreatedAt time.Time        `json:"created_at"`
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"task-pool/internal/domain/entity"
+	"task-pool/internal/domain/repository"
+	"task-pool/internal/service/contracts"
+	"task-pool/pkg/apperror"
+	"time"
+)
+
+type scheduleService struct {
+	scheduleRepository repository.ScheduleRepository
+}
+
+func NewScheduleService(scheduleRepository repository.ScheduleRepository) contracts.ScheduleService {
+	return &scheduleService{
+		scheduleRepository: scheduleRepository,
+	}
+}
+
+func (s *scheduleService) Create(ctx context.Context, command *contracts.CreateSchedule) (*entity.Schedule, error) {
+	schedule, err := entity.NewSchedule(
+		command.Name,
+		command.Cron,
+		command.Title,
+		command.Description,
+		command.Type,
+		command.Payload,
+	)
+	if err != nil {
+		return nil, cronError(err)
+	}
+
+	err = s.scheduleRepository.Create(ctx, schedule)
+	if err != nil {
+		if errors.Is(err, repository.ErrScheduleAlreadyExists) {
+			return nil, apperror.BadRequest("schedule name already exists")
+		}
+
+		return nil, fmt.Errorf("failed to create schedule: %w", err)
+	}
+
+	return schedule, nil
+}
+
+func (s *scheduleService) GetByID(ctx context.Context, id uint64) (*entity.Schedule, error) {
+	schedule, err := s.scheduleRepository.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrScheduleNotFound) {
+			return nil, apperror.NotFound("schedule not found")
+		}
+
+		return nil, fmt.Errorf("failed to get schedule: %w", err)
+	}
+
+	return schedule, nil
+}
+
+func (s *scheduleService) GetAll(ctx context.Context) ([]*entity.Schedule, error) {
+	schedules, err := s.scheduleRepository.FindAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get schedules: %w", err)
+	}
+
+	return schedules, nil
+}
+
+func (s *scheduleService) Update(ctx context.Context, id uint64, command *contracts.UpdateSchedule) (*entity.Schedule, error) {
+	schedule, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	cronChanged := schedule.Cron != command.Cron
+	resumed := !schedule.Enabled && command.Enabled
+
+	schedule.Name = command.Name
+	schedule.Cron = command.Cron
+	schedule.Enabled = command.Enabled
+	schedule.Title = command.Title
+	schedule.Description = command.Description
+	schedule.Type = command.Type
+	schedule.Payload = command.Payload
+
+	// A resumed schedule starts from its next tick instead of firing for the
+	// time it was disabled.
+	if cronChanged || resumed {
+		err = schedule.Advance(time.Now())
+		if err != nil {
+			return nil, cronError(err)
+		}
+	}
+
+	err = s.scheduleRepository.Update(ctx, schedule)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrScheduleNotFound):
+			return nil, apperror.NotFound("schedule not found")
+		case errors.Is(err, repository.ErrScheduleAlreadyExists):
+			return nil, apperror.BadRequest("schedule name already exists")
+		}
+
+		return nil, fmt.Errorf("failed to update schedule: %w", err)
+	}
+
+	return schedule, nil
+}
+
+func (s *scheduleService) Delete(ctx context.Context, id uint64) error {
+	err := s.scheduleRepository.Delete(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrScheduleNotFound) {
+			return apperror.NotFound("schedule not found")
+		}
+
+		return fmt.Errorf("failed to delete schedule: %w", err)
+	}
+
+	return nil
+}
+
+// cronError tells an expression that does not parse from one that never fires.
+func cronError(err error) error {
+	if errors.Is(err, entity.ErrCronNeverFires) {
+		return apperror.BadRequest("cron expression never fires").Wrap(err)
+	}
+
+	return apperror.BadRequest("invalid cron expression").Wrap(err)
+}
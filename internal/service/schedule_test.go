@@ -0,0 +1,184 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"task-pool/internal/domain/entity"
+	"task-pool/internal/domain/repository"
+	"task-pool/internal/service/contracts"
+	"task-pool/pkg/apperror"
+	testmock "task-pool/test/mock"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type scheduleFixture struct {
+	mockRepo *testmock.ScheduleRepository
+	service  contracts.ScheduleService
+	ctx      context.Context
+}
+
+func setupScheduleFixture() *scheduleFixture {
+	mockRepo := testmock.NewScheduleRepository()
+
+	return &scheduleFixture{
+		mockRepo: mockRepo,
+		service:  NewScheduleService(mockRepo),
+		ctx:      context.Background(),
+	}
+}
+
+func newCreateSchedule() *contracts.CreateSchedule {
+	return &contracts.CreateSchedule{
+		Name:        "nightly-report",
+		Cron:        "0 2 * * *",
+		Title:       "Nightly report",
+		Description: "Build the nightly report",
+		Type:        "report",
+		Payload:     []byte(`{"format":"pdf"}`),
+	}
+}
+
+func TestScheduleService_Create(t *testing.T) {
+	t.Run("successful schedule creation", func(t *testing.T) {
+		fixture := setupScheduleFixture()
+
+		createCmd := newCreateSchedule()
+		fixture.mockRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+
+		schedule, err := fixture.service.Create(fixture.ctx, createCmd)
+		require.NoError(t, err)
+		assert.Equal(t, createCmd.Name, schedule.Name)
+		assert.Equal(t, createCmd.Type, schedule.Type)
+		assert.True(t, schedule.Enabled)
+		assert.Equal(t, 2, schedule.NextRunAt.Hour())
+		assert.Equal(t, 0, schedule.NextRunAt.Minute())
+		assert.True(t, schedule.NextRunAt.After(time.Now()))
+
+		fixture.mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("invalid cron expression", func(t *testing.T) {
+		fixture := setupScheduleFixture()
+
+		createCmd := newCreateSchedule()
+		createCmd.Cron = "every night"
+
+		_, err := fixture.service.Create(fixture.ctx, createCmd)
+
+		var appErr *apperror.AppError
+		require.ErrorAs(t, err, &appErr)
+		assert.Equal(t, "BAD_REQUEST", appErr.Code)
+		fixture.mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("cron expression that never fires", func(t *testing.T) {
+		fixture := setupScheduleFixture()
+
+		createCmd := newCreateSchedule()
+		createCmd.Cron = "0 0 30 2 *"
+
+		_, err := fixture.service.Create(fixture.ctx, createCmd)
+
+		var appErr *apperror.AppError
+		require.ErrorAs(t, err, &appErr)
+		assert.Equal(t, "BAD_REQUEST", appErr.Code)
+		assert.Equal(t, "cron expression never fires", appErr.Message)
+		fixture.mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("duplicate name", func(t *testing.T) {
+		fixture := setupScheduleFixture()
+
+		fixture.mockRepo.On("Create", mock.Anything, mock.Anything).Return(repository.ErrScheduleAlreadyExists)
+
+		_, err := fixture.service.Create(fixture.ctx, newCreateSchedule())
+
+		var appErr *apperror.AppError
+		require.ErrorAs(t, err, &appErr)
+		assert.Equal(t, "BAD_REQUEST", appErr.Code)
+	})
+}
+
+func TestScheduleService_Update(t *testing.T) {
+	t.Run("cron change moves next run", func(t *testing.T) {
+		fixture := setupScheduleFixture()
+
+		schedule, err := entity.NewSchedule("nightly-report", "0 2 * * *", "Nightly report", "Build the report", "report", nil)
+		require.NoError(t, err)
+		schedule.ID = 1
+
+		fixture.mockRepo.On("FindByID", mock.Anything, schedule.ID).Return(schedule, nil)
+		fixture.mockRepo.On("Update", mock.Anything, mock.Anything).Return(nil)
+
+		updateCmd := &contracts.UpdateSchedule{CreateSchedule: *newCreateSchedule(), Enabled: true}
+		updateCmd.Cron = "@every 1m"
+
+		updated, err := fixture.service.Update(fixture.ctx, schedule.ID, updateCmd)
+		require.NoError(t, err)
+		assert.Equal(t, "@every 1m", updated.Cron)
+		assert.WithinDuration(t, time.Now().Add(time.Minute), updated.NextRunAt, 2*time.Second)
+
+		fixture.mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("cron change to one that never fires", func(t *testing.T) {
+		fixture := setupScheduleFixture()
+
+		schedule, err := entity.NewSchedule("nightly-report", "0 2 * * *", "Nightly report", "Build the report", "report", nil)
+		require.NoError(t, err)
+		schedule.ID = 1
+
+		fixture.mockRepo.On("FindByID", mock.Anything, schedule.ID).Return(schedule, nil)
+
+		updateCmd := &contracts.UpdateSchedule{CreateSchedule: *newCreateSchedule(), Enabled: true}
+		updateCmd.Cron = "0 0 30 2 *"
+
+		_, err = fixture.service.Update(fixture.ctx, schedule.ID, updateCmd)
+
+		var appErr *apperror.AppError
+		require.ErrorAs(t, err, &appErr)
+		assert.Equal(t, "BAD_REQUEST", appErr.Code)
+		fixture.mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+
+	t.Run("unknown schedule", func(t *testing.T) {
+		fixture := setupScheduleFixture()
+
+		fixture.mockRepo.On("FindByID", mock.Anything, uint64(999)).Return(nil, repository.ErrScheduleNotFound)
+
+		_, err := fixture.service.Update(fixture.ctx, 999, &contracts.UpdateSchedule{CreateSchedule: *newCreateSchedule()})
+
+		var appErr *apperror.AppError
+		require.ErrorAs(t, err, &appErr)
+		assert.Equal(t, "NOT_FOUND", appErr.Code)
+	})
+}
+
+func TestScheduleService_Delete(t *testing.T) {
+	t.Run("unknown schedule", func(t *testing.T) {
+		fixture := setupScheduleFixture()
+
+		fixture.mockRepo.On("Delete", mock.Anything, uint64(999)).Return(repository.ErrScheduleNotFound)
+
+		err := fixture.service.Delete(fixture.ctx, 999)
+
+		var appErr *apperror.AppError
+		require.ErrorAs(t, err, &appErr)
+		assert.Equal(t, "NOT_FOUND", appErr.Code)
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		fixture := setupScheduleFixture()
+
+		dbErr := errors.New("database connection failed")
+		fixture.mockRepo.On("Delete", mock.Anything, uint64(1)).Return(dbErr)
+
+		err := fixture.service.Delete(fixture.ctx, 1)
+		require.ErrorContains(t, err, "failed to delete schedule: "+dbErr.Error())
+	})
+}
@@ -4,35 +4,327 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
+	"strings"
+	"task-pool/config"
 	"task-pool/internal/domain/entity"
 	"task-pool/internal/domain/repository"
+	"task-pool/internal/event"
 	"task-pool/internal/service/contracts"
 	"task-pool/pkg/apperror"
+	"task-pool/pkg/logger"
+	"time"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+	defaultTaskSort = "-" + repository.TaskSortCreatedAt
 )
 
 type taskService struct {
-	taskChannel    chan *entity.Task
-	taskRepository repository.TaskRepository
+	wakeup          map[string]chan struct{}
+	maxPending      int64
+	overflowPolicy  string
+	overflowTimeout time.Duration
+	pollInterval    time.Duration
+	events          event.Publisher
+	taskRepository  repository.TaskRepository
+
+	attemptRepository repository.TaskAttemptRepository
+}
+
+// NewTaskService creates a task service. Created tasks are persisted as
+// pending and picked up by workers from the repository. wakeup holds a channel
+// per configured queue, it is used to reject unknown queues and to let an
+// idle local worker know there is new work. cfg bounds the backlog of every
+// queue, see config.TaskWorker.MaxPending. Every change made to a task is
+// published to events.
+func NewTaskService(
+	taskRepository repository.TaskRepository,
+	attemptRepository repository.TaskAttemptRepository,
+	wakeup map[string]chan struct{},
+	cfg config.TaskWorker,
+	events event.Publisher,
+) contracts.TaskService {
+	s := newTaskService(taskRepository, wakeup, cfg, events)
+	s.attemptRepository = attemptRepository
+
+	return s
 }
 
-func NewTaskService(taskRepository repository.TaskRepository, taskChannel chan *entity.Task) contracts.TaskService {
+// newTaskService creates the part of the task service that validates and
+// admits new tasks, it is shared with the services creating tasks of their own.
+func newTaskService(
+	taskRepository repository.TaskRepository,
+	wakeup map[string]chan struct{},
+	cfg config.TaskWorker,
+	events event.Publisher,
+) *taskService {
 	return &taskService{
-		taskRepository: taskRepository,
-		taskChannel:    taskChannel,
+		wakeup:          wakeup,
+		maxPending:      int64(cfg.MaxPending),
+		overflowPolicy:  cfg.OverflowPolicy,
+		overflowTimeout: cfg.OverflowTimeout,
+		pollInterval:    cfg.PollInterval,
+		events:          events,
+		taskRepository:  taskRepository,
 	}
 }
 
-func (s *taskService) Create(ctx context.Context, command *contracts.CreateTask) error {
-	task := entity.NewTask(command.Title, command.Description, entity.TaskStatusPending)
+func (s *taskService) Create(ctx context.Context, command *contracts.CreateTask) (*entity.Task, bool, error) {
+	task, err := s.newTask(command)
+	if err != nil {
+		return nil, false, err
+	}
+
+	// A replayed request is answered even when the queue is full.
+	if command.IdempotencyKey != "" {
+		original, err := s.findByIdempotencyKey(ctx, command.IdempotencyKey)
+		if err == nil {
+			return original, false, nil
+		}
+		if !errors.Is(err, repository.ErrTaskNotFound) {
+			return nil, false, err
+		}
+	}
+
+	// Delayed tasks do not add to the backlog until they are due.
+	if task.NextRunAt == nil {
+		err = s.admit(ctx, task.Queue, 1)
+		if err != nil {
+			return nil, false, err
+		}
+	}
+
+	err = s.createTask(ctx, task, command.DependsOn)
+	if errors.Is(err, repository.ErrTaskExists) {
+		// A concurrent request with the same key won the race.
+		original, fErr := s.findByIdempotencyKey(ctx, command.IdempotencyKey)
+		if fErr != nil {
+			return nil, false, fErr
+		}
 
-	err := s.taskRepository.Create(ctx, task)
+		return original, false, nil
+	}
 	if err != nil {
-		return fmt.Errorf("failed to create task: %w", err)
+		return nil, false, err
 	}
 
-	s.taskChannel <- task
+	s.publish(task)
 
-	return nil
+	// Delayed tasks are handed to the workers by the scheduler once due,
+	// blocked tasks once their dependencies have completed.
+	if task.NextRunAt == nil && task.Status == entity.TaskStatusPending {
+		s.notify(task.Queue)
+	}
+
+	return task, true, nil
+}
+
+func (s *taskService) createTask(ctx context.Context, task *entity.Task, dependsOn []uint64) error {
+	if len(dependsOn) == 0 {
+		err := s.taskRepository.Create(ctx, task)
+		if err != nil && !errors.Is(err, repository.ErrTaskExists) {
+			return fmt.Errorf("failed to create task: %w", err)
+		}
+
+		return err
+	}
+
+	dependsOn = slices.Clone(dependsOn)
+	slices.Sort(dependsOn)
+
+	err := s.taskRepository.CreateWithDependencies(ctx, task, slices.Compact(dependsOn))
+	switch {
+	case err == nil, errors.Is(err, repository.ErrTaskExists):
+		return err
+	case errors.Is(err, repository.ErrDependencyNotFound), errors.Is(err, repository.ErrDependencyFailed):
+		return apperror.BadRequest("invalid dependency").Wrap(err)
+	}
+
+	return fmt.Errorf("failed to create task: %w", err)
+}
+
+func (s *taskService) CreateBatch(ctx context.Context, command *contracts.CreateTasks) ([]uint64, error) {
+	tasks := make([]*entity.Task, 0, len(command.Tasks))
+	var fields []apperror.FieldError
+
+	for i := range command.Tasks {
+		field := fmt.Sprintf("tasks[%d]", i)
+
+		if command.Tasks[i].IdempotencyKey != "" {
+			fields = append(fields, apperror.FieldError{
+				Field:   field + ".idempotency_key",
+				Message: "idempotency keys are not supported in a batch",
+			})
+			continue
+		}
+
+		if len(command.Tasks[i].DependsOn) > 0 {
+			fields = append(fields, apperror.FieldError{
+				Field:   field + ".depends_on",
+				Message: "dependencies are not supported in a batch",
+			})
+			continue
+		}
+
+		task, err := s.newTask(&command.Tasks[i])
+		if err != nil {
+			var appErr *apperror.AppError
+			if !errors.As(err, &appErr) {
+				return nil, err
+			}
+
+			fields = append(fields, apperror.FieldError{Field: field, Message: appErr.Message})
+			continue
+		}
+
+		tasks = append(tasks, task)
+	}
+
+	if len(fields) > 0 {
+		return nil, apperror.BadRequest("validation failed").WithFields(fields...)
+	}
+
+	// Every queue receiving due tasks must have room for all of them, the
+	// batch is then admitted as a whole.
+	var queues []string
+	due := make(map[string]int64)
+	for _, task := range tasks {
+		if task.NextRunAt != nil {
+			continue
+		}
+
+		if due[task.Queue] == 0 {
+			queues = append(queues, task.Queue)
+		}
+		due[task.Queue]++
+	}
+
+	for _, queue := range queues {
+		err := s.admit(ctx, queue, due[queue])
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err := s.taskRepository.CreateBatch(ctx, tasks)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create tasks: %w", err)
+	}
+
+	s.publish(tasks...)
+
+	for _, queue := range queues {
+		s.notify(queue)
+	}
+
+	ids := make([]uint64, len(tasks))
+	for i, task := range tasks {
+		ids[i] = task.ID
+	}
+
+	return ids, nil
+}
+
+// newTask validates the command and builds the pending task it describes.
+func (s *taskService) newTask(command *contracts.CreateTask) (*entity.Task, error) {
+	runAt, err := scheduledAt(command)
+	if err != nil {
+		return nil, err
+	}
+
+	timeout, err := parseTimeout(command.Timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	queue := command.Queue
+	if queue == "" {
+		queue = entity.DefaultQueue
+	}
+
+	if _, ok := s.wakeup[queue]; !ok {
+		return nil, apperror.BadRequest(fmt.Sprintf("unknown queue %q", queue))
+	}
+
+	task := entity.NewTask(command.Title, command.Description, command.Type, command.Payload, entity.TaskStatusPending)
+	task.Queue = queue
+	task.Priority = command.Priority
+	task.MaxAttempts = command.MaxAttempts
+	task.NextRunAt = runAt
+	task.Timeout = entity.Duration(timeout)
+	if command.IdempotencyKey != "" {
+		task.IdempotencyKey = &command.IdempotencyKey
+	}
+
+	return task, nil
+}
+
+func (s *taskService) findByIdempotencyKey(ctx context.Context, key string) (*entity.Task, error) {
+	task, err := s.taskRepository.FindByIdempotencyKey(ctx, key)
+	if err != nil {
+		if errors.Is(err, repository.ErrTaskNotFound) {
+			return nil, err
+		}
+
+		return nil, fmt.Errorf("failed to get task by idempotency key: %w", err)
+	}
+
+	return task, nil
+}
+
+// scheduledAt resolves RunAt or Delay into the first run time of the task, nil
+// means the task is due immediately.
+func scheduledAt(command *contracts.CreateTask) (*time.Time, error) {
+	if command.RunAt != nil && command.Delay != "" {
+		return nil, apperror.BadRequest("run_at and delay are mutually exclusive")
+	}
+
+	if command.RunAt != nil {
+		if !command.RunAt.After(time.Now()) {
+			return nil, nil
+		}
+
+		return command.RunAt, nil
+	}
+
+	if command.Delay != "" {
+		delay, err := time.ParseDuration(command.Delay)
+		if err != nil {
+			return nil, apperror.BadRequest("invalid delay").Wrap(err)
+		}
+		if delay < 0 {
+			return nil, apperror.BadRequest("delay must not be negative")
+		}
+		if delay == 0 {
+			return nil, nil
+		}
+
+		runAt := time.Now().Add(delay)
+		return &runAt, nil
+	}
+
+	return nil, nil
+}
+
+// parseTimeout parses the per task execution timeout, zero means the worker
+// default applies.
+func parseTimeout(value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+
+	timeout, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, apperror.BadRequest("invalid timeout").Wrap(err)
+	}
+	if timeout <= 0 {
+		return 0, apperror.BadRequest("timeout must be positive")
+	}
+
+	return timeout, nil
 }
 
 func (s *taskService) GetByID(ctx context.Context, id uint64) (*entity.Task, error) {
@@ -48,11 +340,291 @@ func (s *taskService) GetByID(ctx context.Context, id uint64) (*entity.Task, err
 	return task, nil
 }
 
-func (s *taskService) GetAll(ctx context.Context) ([]*entity.Task, error) {
-	tasks, err := s.taskRepository.FindAll(ctx)
+func (s *taskService) GetAll(ctx context.Context, query *contracts.ListTasks) (*contracts.TaskPage, error) {
+	filter, err := taskFilter(query)
 	if err != nil {
+		return nil, err
+	}
+
+	page, err := s.taskRepository.FindAll(ctx, filter)
+	if err != nil {
+		if errors.Is(err, repository.ErrInvalidCursor) {
+			return nil, apperror.BadRequest("invalid cursor").Wrap(err)
+		}
+
 		return nil, fmt.Errorf("failed to get tasks: %w", err)
 	}
 
+	return &contracts.TaskPage{
+		Items:      page.Tasks,
+		NextCursor: page.NextCursor,
+		Total:      page.Total,
+	}, nil
+}
+
+// taskFilter validates the listing query and applies its defaults.
+func taskFilter(query *contracts.ListTasks) (repository.TaskFilter, error) {
+	filter := repository.TaskFilter{
+		Status: entity.TaskStatus(query.Status),
+		Type:   query.Type,
+		Queue:  query.Queue,
+		Cursor: query.Cursor,
+		Limit:  query.Limit,
+	}
+
+	if filter.Status != "" && !filter.Status.IsValid() {
+		return filter, apperror.BadRequest(fmt.Sprintf("unknown status %q", query.Status))
+	}
+
+	var err error
+	filter.CreatedAfter, err = parseTime("created_after", query.CreatedAfter)
+	if err != nil {
+		return filter, err
+	}
+
+	filter.CreatedBefore, err = parseTime("created_before", query.CreatedBefore)
+	if err != nil {
+		return filter, err
+	}
+
+	sort := query.Sort
+	if sort == "" {
+		sort = defaultTaskSort
+	}
+
+	filter.Sort, filter.Desc = strings.CutPrefix(sort, "-")
+	if !slices.Contains(repository.TaskSorts, filter.Sort) {
+		return filter, apperror.BadRequest(fmt.Sprintf("unknown sort %q", query.Sort))
+	}
+
+	if filter.Limit == 0 {
+		filter.Limit = defaultPageSize
+	}
+	if filter.Limit < 0 || filter.Limit > maxPageSize {
+		return filter, apperror.BadRequest(fmt.Sprintf("limit must be between 1 and %d", maxPageSize))
+	}
+
+	return filter, nil
+}
+
+// parseTime parses an optional RFC 3339 query parameter.
+func parseTime(name, value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, apperror.BadRequest("invalid " + name).Wrap(err)
+	}
+
+	return &t, nil
+}
+
+func (s *taskService) GetDeadLettered(ctx context.Context) ([]*entity.Task, error) {
+	tasks, err := s.taskRepository.FindDeadLettered(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dead-lettered tasks: %w", err)
+	}
+
 	return tasks, nil
 }
+
+func (s *taskService) GetGraph(ctx context.Context, id uint64) (*contracts.TaskGraph, error) {
+	tasks, dependencies, err := s.taskRepository.FindGraph(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrTaskNotFound) {
+			return nil, apperror.NotFound("task not found")
+		}
+
+		return nil, fmt.Errorf("failed to get task graph: %w", err)
+	}
+
+	graph := &contracts.TaskGraph{
+		Nodes: make([]contracts.TaskGraphNode, 0, len(tasks)),
+		Edges: make([]contracts.TaskGraphEdge, 0, len(dependencies)),
+	}
+
+	for _, task := range tasks {
+		graph.Nodes = append(graph.Nodes, contracts.TaskGraphNode{
+			ID:     task.ID,
+			Title:  task.Title,
+			Type:   task.Type,
+			Queue:  task.Queue,
+			Status: task.Status,
+		})
+	}
+
+	for _, dependency := range dependencies {
+		graph.Edges = append(graph.Edges, contracts.TaskGraphEdge{From: dependency.DependsOnID, To: dependency.TaskID})
+	}
+
+	return graph, nil
+}
+
+func (s *taskService) GetAttempts(ctx context.Context, id uint64) ([]*entity.TaskAttempt, error) {
+	_, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	attempts, err := s.attemptRepository.FindByTaskID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get task attempts: %w", err)
+	}
+
+	return attempts, nil
+}
+
+func (s *taskService) Cancel(ctx context.Context, id uint64) error {
+	task, err := s.taskRepository.Cancel(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrTaskNotFound) {
+			return apperror.NotFound("task not found")
+		}
+
+		if errors.Is(err, repository.ErrTaskNotCancellable) {
+			return apperror.BadRequest("task has already finished")
+		}
+
+		return fmt.Errorf("failed to cancel task: %w", err)
+	}
+
+	s.publish(task)
+
+	// Tasks waiting for the cancelled task can no longer run. The cancel is
+	// already saved, so a failure here is left to the recovery sweep.
+	dependents, err := s.taskRepository.ResolveDependents(context.WithoutCancel(ctx), task)
+	if err != nil {
+		logger.Error("Error cancelling dependent tasks").WithUint64("task_id", task.ID).WithError(err).Log()
+		return nil
+	}
+
+	s.publish(dependents...)
+
+	return nil
+}
+
+func (s *taskService) Requeue(ctx context.Context, id uint64) error {
+	task, err := s.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if !task.IsDeadLettered() {
+		return apperror.BadRequest("task is not in the dead-letter queue")
+	}
+
+	err = task.Requeue()
+	if err != nil {
+		return apperror.BadRequest("task cannot be requeued").Wrap(err)
+	}
+
+	// The task is only requeued if it is still dead-lettered.
+	requeued, err := s.taskRepository.RequeueDeadLettered(ctx, []uint64{task.ID})
+	if err != nil {
+		return fmt.Errorf("failed to requeue task: %w", err)
+	}
+
+	if len(requeued) == 0 {
+		return apperror.BadRequest("task is not in the dead-letter queue")
+	}
+
+	s.publish(requeued...)
+	s.notify(task.Queue)
+
+	return nil
+}
+
+func (s *taskService) RequeueDeadLettered(ctx context.Context, command *contracts.RequeueTasks) (int64, error) {
+	requeued, err := s.taskRepository.RequeueDeadLettered(ctx, command.IDs)
+	if err != nil {
+		return 0, fmt.Errorf("failed to requeue tasks: %w", err)
+	}
+
+	s.publish(requeued...)
+
+	var queues []string
+	for _, task := range requeued {
+		if !slices.Contains(queues, task.Queue) {
+			queues = append(queues, task.Queue)
+			s.notify(task.Queue)
+		}
+	}
+
+	return int64(len(requeued)), nil
+}
+
+func (s *taskService) PurgeDeadLettered(ctx context.Context) (int64, error) {
+	count, err := s.taskRepository.PurgeDeadLettered(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge tasks: %w", err)
+	}
+
+	return count, nil
+}
+
+// admit applies the overflow policy when the due backlog of queue has no room
+// left for n more tasks under the configured limit. Block waits for workers
+// to make room, polling until the overflow timeout or the request context
+// ends. The limit is approximate, concurrent requests that all find room may
+// together go over it.
+func (s *taskService) admit(ctx context.Context, queue string, n int64) error {
+	if s.maxPending <= 0 || s.overflowPolicy == config.OverflowSpill {
+		return nil
+	}
+
+	// No amount of waiting makes room for more than the limit.
+	if n > s.maxPending {
+		return apperror.BadRequest(fmt.Sprintf("%d tasks exceed the limit of %d pending tasks of queue %q", n, s.maxPending, queue))
+	}
+
+	retryAfter := max(s.pollInterval, time.Second)
+
+	var deadline <-chan time.Time
+	if s.overflowPolicy == config.OverflowBlock {
+		timer := time.NewTimer(s.overflowTimeout)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+
+	for {
+		count, err := s.taskRepository.CountPending(ctx, queue)
+		if err != nil {
+			return fmt.Errorf("failed to count pending tasks: %w", err)
+		}
+
+		if count+n <= s.maxPending {
+			return nil
+		}
+
+		if s.overflowPolicy == config.OverflowReject {
+			return apperror.TooManyRequests(fmt.Sprintf("queue %q is full", queue)).WithRetryAfter(retryAfter)
+		}
+
+		select {
+		case <-ctx.Done():
+			return apperror.ServiceUnavailable(fmt.Sprintf("queue %q is full", queue)).
+				WithRetryAfter(retryAfter).
+				Wrap(ctx.Err())
+		case <-deadline:
+			return apperror.ServiceUnavailable(fmt.Sprintf("queue %q is full", queue)).WithRetryAfter(retryAfter)
+		case <-time.After(s.pollInterval):
+		}
+	}
+}
+
+// publish lets the subscribers know about the new status of tasks.
+func (s *taskService) publish(tasks ...*entity.Task) {
+	for _, task := range tasks {
+		s.events.Publish(event.NewTaskEvent(event.TypeStatus, task))
+	}
+}
+
+// notify wakes up an idle local worker of queue without blocking the caller.
+func (s *taskService) notify(queue string) {
+	select {
+	case s.wakeup[queue] <- struct{}{}:
+	default:
+	}
+}
@@ -5,8 +5,10 @@ import (
 	"errors"
 	"fmt"
 	"sync"
+	"task-pool/config"
 	"task-pool/internal/domain/entity"
 	"task-pool/internal/domain/repository"
+	"task-pool/internal/event"
 	"task-pool/internal/service/contracts"
 	"task-pool/pkg/apperror"
 	testmock "task-pool/test/mock"
@@ -19,10 +21,12 @@ import (
 )
 
 type testFixture struct {
-	mockRepo    *testmock.TaskRepository
-	taskChannel chan *entity.Task
-	service     contracts.TaskService
-	ctx         context.Context
+	mockRepo        *testmock.TaskRepository
+	mockAttemptRepo *testmock.TaskAttemptRepository
+	wakeup          chan struct{}
+	bus             *event.Bus
+	service         contracts.TaskService
+	ctx             context.Context
 }
 
 func setupFixture(channelSize ...int) *testFixture {
@@ -32,14 +36,21 @@ func setupFixture(channelSize ...int) *testFixture {
 	}
 
 	mockRepo := testmock.NewTaskRepository()
-	taskChannel := make(chan *entity.Task, size)
-	service := NewTaskService(mockRepo, taskChannel)
+	mockAttemptRepo := testmock.NewTaskAttemptRepository()
+	wakeup := make(chan struct{}, size)
+	bus := event.NewBus(size)
+	service := NewTaskService(mockRepo, mockAttemptRepo, map[string]chan struct{}{
+		entity.DefaultQueue: wakeup,
+		"emails":            make(chan struct{}, size),
+	}, config.TaskWorker{OverflowPolicy: config.OverflowSpill}, bus)
 
 	return &testFixture{
-		mockRepo:    mockRepo,
-		taskChannel: taskChannel,
-		service:     service,
-		ctx:         context.Background(),
+		mockRepo:        mockRepo,
+		mockAttemptRepo: mockAttemptRepo,
+		wakeup:          wakeup,
+		bus:             bus,
+		service:         service,
+		ctx:             context.Background(),
 	}
 }
 
@@ -50,20 +61,37 @@ func TestTaskService_Create(t *testing.T) {
 		createCmd := &contracts.CreateTask{
 			Title:       "Test Task",
 			Description: "Test Description",
+			Type:        "send-email",
+			Payload:     []byte(`{"to":"user@example.com"}`),
+			Priority:    7,
+			MaxAttempts: 5,
 		}
 
-		fixture.mockRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+		fixture.mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(task *entity.Task) bool {
+			return task.Title == createCmd.Title &&
+				task.Description == createCmd.Description &&
+				task.Type == createCmd.Type &&
+				string(task.Payload) == string(createCmd.Payload) &&
+				task.Queue == entity.DefaultQueue &&
+				task.Priority == createCmd.Priority &&
+				task.MaxAttempts == createCmd.MaxAttempts &&
+				task.Status == entity.TaskStatusPending
+		})).Return(nil)
+		subscription := fixture.bus.Subscribe(0)
 
-		err := fixture.service.Create(fixture.ctx, createCmd)
+		task, created, err := fixture.service.Create(fixture.ctx, createCmd)
 		require.NoError(t, err)
+		assert.True(t, created)
+		assert.Equal(t, createCmd.Title, task.Title)
+		assert.Nil(t, task.IdempotencyKey)
+
+		require.Len(t, subscription.Events(), 1)
+		assert.Equal(t, entity.TaskStatusPending, (<-subscription.Events()).Status)
 
 		select {
-		case task := <-fixture.taskChannel:
-			assert.Equal(t, createCmd.Title, task.Title)
-			assert.Equal(t, createCmd.Description, task.Description)
-			assert.Equal(t, entity.TaskStatusPending, task.Status)
+		case <-fixture.wakeup:
 		case <-time.After(1 * time.Second):
-			t.Fatal("task was not sent to channel")
+			t.Fatal("workers were not woken up")
 		}
 
 		fixture.mockRepo.AssertExpectations(t)
@@ -79,22 +107,434 @@ func TestTaskService_Create(t *testing.T) {
 
 		expectedError := errors.New("database connection failed")
 		fixture.mockRepo.On("Create", mock.Anything, mock.Anything).Return(expectedError)
+		subscription := fixture.bus.Subscribe(0)
 
-		err := fixture.service.Create(fixture.ctx, createCmd)
+		_, _, err := fixture.service.Create(fixture.ctx, createCmd)
 		require.Error(t, err)
 		assert.Contains(t, err.Error(), "failed to create task")
 		assert.Contains(t, err.Error(), "database connection failed")
+		assert.Empty(t, subscription.Events())
 
-		// Verify task was not sent to channel
+		// Verify workers were not woken up
 		select {
-		case <-fixture.taskChannel:
-			t.Fatal("task should not be sent to channel when repository fails")
+		case <-fixture.wakeup:
+			t.Fatal("workers should not be woken up when repository fails")
 		default:
 			// Expected: channel should be empty
 		}
 
 		fixture.mockRepo.AssertExpectations(t)
 	})
+
+	t.Run("delayed task is scheduled and does not wake workers", func(t *testing.T) {
+		fixture := setupFixture()
+
+		fixture.mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(task *entity.Task) bool {
+			return task.NextRunAt != nil &&
+				task.NextRunAt.After(time.Now().Add(4*time.Minute)) &&
+				task.NextRunAt.Before(time.Now().Add(6*time.Minute))
+		})).Return(nil)
+
+		_, _, err := fixture.service.Create(fixture.ctx, &contracts.CreateTask{
+			Title:       "Test Task",
+			Description: "Test Description",
+			Delay:       "5m",
+		})
+		require.NoError(t, err)
+		assert.Empty(t, fixture.wakeup)
+
+		fixture.mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("task scheduled at a future time", func(t *testing.T) {
+		fixture := setupFixture()
+
+		runAt := time.Now().Add(time.Hour).Truncate(time.Second)
+		fixture.mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(task *entity.Task) bool {
+			return task.NextRunAt != nil && task.NextRunAt.Equal(runAt)
+		})).Return(nil)
+
+		_, _, err := fixture.service.Create(fixture.ctx, &contracts.CreateTask{
+			Title:       "Test Task",
+			Description: "Test Description",
+			RunAt:       &runAt,
+		})
+		require.NoError(t, err)
+
+		fixture.mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("run_at in the past runs immediately", func(t *testing.T) {
+		fixture := setupFixture()
+
+		runAt := time.Now().Add(-time.Hour)
+		fixture.mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(task *entity.Task) bool {
+			return task.NextRunAt == nil
+		})).Return(nil)
+
+		_, _, err := fixture.service.Create(fixture.ctx, &contracts.CreateTask{
+			Title:       "Test Task",
+			Description: "Test Description",
+			RunAt:       &runAt,
+		})
+		require.NoError(t, err)
+		assert.Len(t, fixture.wakeup, 1)
+
+		fixture.mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("invalid schedule is rejected", func(t *testing.T) {
+		runAt := time.Now().Add(time.Hour)
+
+		for name, createCmd := range map[string]*contracts.CreateTask{
+			"run_at and delay": {Title: "Test Task", Description: "Test Description", RunAt: &runAt, Delay: "5m"},
+			"malformed delay":  {Title: "Test Task", Description: "Test Description", Delay: "five minutes"},
+			"negative delay":   {Title: "Test Task", Description: "Test Description", Delay: "-5m"},
+		} {
+			t.Run(name, func(t *testing.T) {
+				fixture := setupFixture()
+
+				_, _, err := fixture.service.Create(fixture.ctx, createCmd)
+
+				var appErr *apperror.AppError
+				require.ErrorAs(t, err, &appErr)
+				assert.Equal(t, "BAD_REQUEST", appErr.Code)
+				fixture.mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+			})
+		}
+	})
+
+	t.Run("task timeout is parsed", func(t *testing.T) {
+		fixture := setupFixture()
+
+		fixture.mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(task *entity.Task) bool {
+			return task.Timeout == entity.Duration(90*time.Second)
+		})).Return(nil)
+
+		_, _, err := fixture.service.Create(fixture.ctx, &contracts.CreateTask{
+			Title:       "Test Task",
+			Description: "Test Description",
+			Timeout:     "90s",
+		})
+		require.NoError(t, err)
+		fixture.mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("invalid timeout", func(t *testing.T) {
+		for _, timeout := range []string{"soon", "0s", "-1m"} {
+			fixture := setupFixture()
+
+			_, _, err := fixture.service.Create(fixture.ctx, &contracts.CreateTask{
+				Title:       "Test Task",
+				Description: "Test Description",
+				Timeout:     timeout,
+			})
+
+			var appErr *apperror.AppError
+			require.ErrorAs(t, err, &appErr, timeout)
+			assert.Equal(t, "BAD_REQUEST", appErr.Code)
+			fixture.mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		}
+	})
+
+	t.Run("task is created in the requested queue", func(t *testing.T) {
+		fixture := setupFixture()
+
+		fixture.mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(task *entity.Task) bool {
+			return task.Queue == "emails"
+		})).Return(nil)
+
+		_, _, err := fixture.service.Create(fixture.ctx, &contracts.CreateTask{
+			Title:       "Test Task",
+			Description: "Test Description",
+			Queue:       "emails",
+		})
+		require.NoError(t, err)
+
+		// Only the workers of the target queue are woken up
+		assert.Empty(t, fixture.wakeup)
+		fixture.mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("unknown queue", func(t *testing.T) {
+		fixture := setupFixture()
+
+		_, _, err := fixture.service.Create(fixture.ctx, &contracts.CreateTask{
+			Title:       "Test Task",
+			Description: "Test Description",
+			Queue:       "reports",
+		})
+
+		var appErr *apperror.AppError
+		require.ErrorAs(t, err, &appErr)
+		assert.Equal(t, "BAD_REQUEST", appErr.Code)
+		fixture.mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("create does not block when wakeup channel is full", func(t *testing.T) {
+		fixture := setupFixture(1)
+		fixture.wakeup <- struct{}{}
+
+		fixture.mockRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+
+		done := make(chan error, 1)
+		go func() {
+			_, _, err := fixture.service.Create(fixture.ctx, &contracts.CreateTask{Title: "Test Task", Description: "Test Description"})
+			done <- err
+		}()
+
+		select {
+		case err := <-done:
+			require.NoError(t, err)
+		case <-time.After(1 * time.Second):
+			t.Fatal("create blocked on a full wakeup channel")
+		}
+
+		fixture.mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestTaskService_CreateIdempotent(t *testing.T) {
+	createCmd := func() *contracts.CreateTask {
+		return &contracts.CreateTask{
+			Title:          "Test Task",
+			Description:    "Test Description",
+			IdempotencyKey: "order-1234",
+		}
+	}
+
+	t.Run("first request creates the task", func(t *testing.T) {
+		fixture := setupFixture()
+
+		fixture.mockRepo.On("FindByIdempotencyKey", mock.Anything, "order-1234").Return(nil, repository.ErrTaskNotFound)
+		fixture.mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(task *entity.Task) bool {
+			return task.IdempotencyKey != nil && *task.IdempotencyKey == "order-1234"
+		})).Return(nil)
+
+		task, created, err := fixture.service.Create(fixture.ctx, createCmd())
+		require.NoError(t, err)
+		assert.True(t, created)
+		assert.Equal(t, "order-1234", *task.IdempotencyKey)
+
+		fixture.mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("repeated request returns the original task", func(t *testing.T) {
+		fixture := setupFixture()
+
+		key := "order-1234"
+		original := &entity.Task{ID: 7, Title: "Test Task", Status: entity.TaskStatusRunning, IdempotencyKey: &key}
+		fixture.mockRepo.On("FindByIdempotencyKey", mock.Anything, "order-1234").Return(original, nil)
+
+		task, created, err := fixture.service.Create(fixture.ctx, createCmd())
+		require.NoError(t, err)
+		assert.False(t, created)
+		assert.Equal(t, original, task)
+
+		fixture.mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		assert.Empty(t, fixture.wakeup)
+	})
+
+	t.Run("concurrent request with the same key wins the race", func(t *testing.T) {
+		fixture := setupFixture()
+
+		key := "order-1234"
+		original := &entity.Task{ID: 7, IdempotencyKey: &key}
+		fixture.mockRepo.On("FindByIdempotencyKey", mock.Anything, "order-1234").Return(nil, repository.ErrTaskNotFound).Once()
+		fixture.mockRepo.On("Create", mock.Anything, mock.Anything).Return(repository.ErrTaskExists)
+		fixture.mockRepo.On("FindByIdempotencyKey", mock.Anything, "order-1234").Return(original, nil).Once()
+
+		task, created, err := fixture.service.Create(fixture.ctx, createCmd())
+		require.NoError(t, err)
+		assert.False(t, created)
+		assert.Equal(t, original, task)
+
+		fixture.mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("lookup error", func(t *testing.T) {
+		fixture := setupFixture()
+
+		dbErr := errors.New("database connection failed")
+		fixture.mockRepo.On("FindByIdempotencyKey", mock.Anything, "order-1234").Return(nil, dbErr)
+
+		task, _, err := fixture.service.Create(fixture.ctx, createCmd())
+		assert.Nil(t, task)
+		require.ErrorIs(t, err, dbErr)
+
+		fixture.mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+}
+
+func TestTaskService_Dependencies(t *testing.T) {
+	createCmd := func(dependsOn ...uint64) *contracts.CreateTask {
+		return &contracts.CreateTask{
+			Title:       "Test Task",
+			Description: "Test Description",
+			DependsOn:   dependsOn,
+		}
+	}
+
+	t.Run("task waiting for dependencies is created blocked", func(t *testing.T) {
+		fixture := setupFixture()
+
+		fixture.mockRepo.On("CreateWithDependencies", mock.Anything, mock.Anything, []uint64{1, 3}).
+			Run(func(args mock.Arguments) {
+				args.Get(1).(*entity.Task).Status = entity.TaskStatusBlocked
+			}).Return(nil)
+
+		task, created, err := fixture.service.Create(fixture.ctx, createCmd(3, 1, 3))
+		require.NoError(t, err)
+		assert.True(t, created)
+		assert.Equal(t, entity.TaskStatusBlocked, task.Status)
+
+		// Blocked tasks are handed to the workers once their dependencies complete
+		assert.Empty(t, fixture.wakeup)
+		fixture.mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("task whose dependencies have completed is pending", func(t *testing.T) {
+		fixture := setupFixture()
+
+		fixture.mockRepo.On("CreateWithDependencies", mock.Anything, mock.Anything, []uint64{1}).Return(nil)
+
+		task, _, err := fixture.service.Create(fixture.ctx, createCmd(1))
+		require.NoError(t, err)
+		assert.Equal(t, entity.TaskStatusPending, task.Status)
+
+		select {
+		case <-fixture.wakeup:
+		case <-time.After(1 * time.Second):
+			t.Fatal("workers were not woken up")
+		}
+	})
+
+	t.Run("missing or failed dependency", func(t *testing.T) {
+		for _, depErr := range []error{repository.ErrDependencyNotFound, repository.ErrDependencyFailed} {
+			fixture := setupFixture()
+
+			fixture.mockRepo.On("CreateWithDependencies", mock.Anything, mock.Anything, []uint64{9}).
+				Return(fmt.Errorf("%w: task 9", depErr))
+
+			task, _, err := fixture.service.Create(fixture.ctx, createCmd(9))
+			assert.Nil(t, task)
+
+			var appErr *apperror.AppError
+			require.ErrorAs(t, err, &appErr)
+			assert.Equal(t, "BAD_REQUEST", appErr.Code)
+		}
+	})
+
+	t.Run("graph of connected tasks", func(t *testing.T) {
+		fixture := setupFixture()
+
+		fixture.mockRepo.On("FindGraph", mock.Anything, uint64(2)).Return(
+			[]*entity.Task{
+				{ID: 1, Title: "Extract", Status: entity.TaskStatusCompleted},
+				{ID: 2, Title: "Transform", Status: entity.TaskStatusRunning},
+				{ID: 3, Title: "Load", Status: entity.TaskStatusBlocked},
+			},
+			[]*entity.TaskDependency{{TaskID: 2, DependsOnID: 1}, {TaskID: 3, DependsOnID: 2}},
+			nil,
+		)
+
+		graph, err := fixture.service.GetGraph(fixture.ctx, 2)
+		require.NoError(t, err)
+		assert.Len(t, graph.Nodes, 3)
+		assert.Equal(t, entity.TaskStatusBlocked, graph.Nodes[2].Status)
+		assert.Equal(t, []contracts.TaskGraphEdge{{From: 1, To: 2}, {From: 2, To: 3}}, graph.Edges)
+	})
+
+	t.Run("graph of unknown task", func(t *testing.T) {
+		fixture := setupFixture()
+
+		fixture.mockRepo.On("FindGraph", mock.Anything, uint64(999)).Return(nil, nil, repository.ErrTaskNotFound)
+
+		_, err := fixture.service.GetGraph(fixture.ctx, 999)
+
+		var appErr *apperror.AppError
+		require.ErrorAs(t, err, &appErr)
+		assert.Equal(t, "NOT_FOUND", appErr.Code)
+	})
+}
+
+func TestTaskService_CreateBatch(t *testing.T) {
+	newCommand := func(tasks ...contracts.CreateTask) *contracts.CreateTasks {
+		return &contracts.CreateTasks{Tasks: tasks}
+	}
+	valid := contracts.CreateTask{Title: "Test Task", Description: "Test Description", Type: "send-email"}
+
+	t.Run("all tasks are created in one call", func(t *testing.T) {
+		fixture := setupFixture()
+
+		emails := valid
+		emails.Queue = "emails"
+		delayed := valid
+		delayed.Delay = "1h"
+
+		fixture.mockRepo.On("CreateBatch", mock.Anything, mock.MatchedBy(func(tasks []*entity.Task) bool {
+			return len(tasks) == 3 &&
+				tasks[0].Queue == entity.DefaultQueue &&
+				tasks[1].Queue == "emails" &&
+				tasks[2].NextRunAt != nil
+		})).Run(func(args mock.Arguments) {
+			for i, task := range args.Get(1).([]*entity.Task) {
+				task.ID = uint64(10 + i)
+			}
+		}).Return(nil)
+
+		ids, err := fixture.service.CreateBatch(fixture.ctx, newCommand(valid, emails, delayed))
+		require.NoError(t, err)
+		assert.Equal(t, []uint64{10, 11, 12}, ids)
+
+		select {
+		case <-fixture.wakeup:
+		case <-time.After(1 * time.Second):
+			t.Fatal("workers were not woken up")
+		}
+
+		fixture.mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("invalid tasks are reported by index", func(t *testing.T) {
+		fixture := setupFixture()
+
+		unknownQueue := valid
+		unknownQueue.Queue = "sms"
+		badDelay := valid
+		badDelay.Delay = "soon"
+		withKey := valid
+		withKey.IdempotencyKey = "order-1234"
+		withDependency := valid
+		withDependency.DependsOn = []uint64{1}
+
+		ids, err := fixture.service.CreateBatch(fixture.ctx, newCommand(valid, unknownQueue, badDelay, withKey, withDependency))
+		assert.Nil(t, ids)
+
+		var appErr *apperror.AppError
+		require.ErrorAs(t, err, &appErr)
+		assert.Equal(t, "BAD_REQUEST", appErr.Code)
+		assert.Equal(t, []apperror.FieldError{
+			{Field: "tasks[1]", Message: `unknown queue "sms"`},
+			{Field: "tasks[2]", Message: "invalid delay"},
+			{Field: "tasks[3].idempotency_key", Message: "idempotency keys are not supported in a batch"},
+			{Field: "tasks[4].depends_on", Message: "dependencies are not supported in a batch"},
+		}, appErr.Fields)
+
+		fixture.mockRepo.AssertNotCalled(t, "CreateBatch", mock.Anything, mock.Anything)
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		fixture := setupFixture()
+
+		dbErr := errors.New("database connection failed")
+		fixture.mockRepo.On("CreateBatch", mock.Anything, mock.Anything).Return(dbErr)
+
+		ids, err := fixture.service.CreateBatch(fixture.ctx, newCommand(valid))
+		assert.Nil(t, ids)
+		require.ErrorIs(t, err, dbErr)
+		assert.Empty(t, fixture.wakeup)
+	})
 }
 
 func TestTaskService_GetByID(t *testing.T) {
@@ -132,7 +572,7 @@ func TestTaskService_GetByID(t *testing.T) {
 
 		_, err := fixture.service.GetByID(fixture.ctx, testID)
 		require.Error(t, err)
-		
+
 		var appErr *apperror.AppError
 		assert.ErrorAs(t, err, &appErr)
 		assert.Equal(t, "NOT_FOUND", appErr.Code)
@@ -157,48 +597,461 @@ func TestTaskService_GetByID(t *testing.T) {
 }
 
 func TestTaskService_GetAll(t *testing.T) {
-	t.Run("successful retrieval of all tasks", func(t *testing.T) {
+	t.Run("first page with defaults", func(t *testing.T) {
 		fixture := setupFixture()
 
 		expectedTasks := []*entity.Task{
-			{ID: 1, Title: "Task 1", Description: "Description 1", Status: entity.TaskStatusPending},
 			{ID: 2, Title: "Task 2", Description: "Description 2", Status: entity.TaskStatusPending},
+			{ID: 1, Title: "Task 1", Description: "Description 1", Status: entity.TaskStatusPending},
 		}
-		fixture.mockRepo.On("FindAll", mock.Anything).Return(expectedTasks, nil)
+		fixture.mockRepo.On("FindAll", mock.Anything, repository.TaskFilter{
+			Sort:  repository.TaskSortCreatedAt,
+			Desc:  true,
+			Limit: 20,
+		}).Return(&repository.TaskPage{Tasks: expectedTasks, NextCursor: "next", Total: 42}, nil)
 
-		allTasks, err := fixture.service.GetAll(fixture.ctx)
+		page, err := fixture.service.GetAll(fixture.ctx, &contracts.ListTasks{})
 		require.NoError(t, err)
-		assert.Len(t, allTasks, 2)
+		assert.Equal(t, expectedTasks, page.Items)
+		assert.Equal(t, "next", page.NextCursor)
+		assert.Equal(t, int64(42), page.Total)
 
 		fixture.mockRepo.AssertExpectations(t)
 	})
 
-	t.Run("empty list when no tasks exist", func(t *testing.T) {
+	t.Run("filters, sort and cursor are passed to the repository", func(t *testing.T) {
 		fixture := setupFixture()
 
-		fixture.mockRepo.On("FindAll", mock.Anything).Return([]*entity.Task{}, nil)
+		after := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		before := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+		fixture.mockRepo.On("FindAll", mock.Anything, repository.TaskFilter{
+			Status:        entity.TaskStatusFailed,
+			Type:          "send-email",
+			Queue:         "emails",
+			CreatedAfter:  &after,
+			CreatedBefore: &before,
+			Sort:          repository.TaskSortPriority,
+			Cursor:        "abc",
+			Limit:         5,
+		}).Return(&repository.TaskPage{Tasks: []*entity.Task{}}, nil)
 
-		allTasks, err := fixture.service.GetAll(fixture.ctx)
+		page, err := fixture.service.GetAll(fixture.ctx, &contracts.ListTasks{
+			Status:        "failed",
+			Type:          "send-email",
+			Queue:         "emails",
+			CreatedAfter:  "2024-01-01T00:00:00Z",
+			CreatedBefore: "2024-02-01T00:00:00Z",
+			Sort:          "priority",
+			Cursor:        "abc",
+			Limit:         5,
+		})
 		require.NoError(t, err)
-		assert.Empty(t, allTasks)
+		assert.Empty(t, page.Items)
+		assert.Empty(t, page.NextCursor)
 
 		fixture.mockRepo.AssertExpectations(t)
 	})
 
+	t.Run("invalid query", func(t *testing.T) {
+		for name, query := range map[string]*contracts.ListTasks{
+			"status":        {Status: "done"},
+			"created_after": {CreatedAfter: "yesterday"},
+			"sort":          {Sort: "-title"},
+			"limit":         {Limit: 101},
+		} {
+			t.Run(name, func(t *testing.T) {
+				fixture := setupFixture()
+
+				page, err := fixture.service.GetAll(fixture.ctx, query)
+				assert.Nil(t, page)
+
+				var appErr *apperror.AppError
+				require.ErrorAs(t, err, &appErr)
+				assert.Equal(t, "BAD_REQUEST", appErr.Code)
+
+				fixture.mockRepo.AssertNotCalled(t, "FindAll", mock.Anything, mock.Anything)
+			})
+		}
+	})
+
+	t.Run("invalid cursor", func(t *testing.T) {
+		fixture := setupFixture()
+
+		fixture.mockRepo.On("FindAll", mock.Anything, mock.Anything).Return(nil, repository.ErrInvalidCursor)
+
+		_, err := fixture.service.GetAll(fixture.ctx, &contracts.ListTasks{Cursor: "garbage"})
+
+		var appErr *apperror.AppError
+		require.ErrorAs(t, err, &appErr)
+		assert.Equal(t, "BAD_REQUEST", appErr.Code)
+	})
+
 	t.Run("failed to get tasks", func(t *testing.T) {
 		fixture := setupFixture()
 
 		dbErr := errors.New("database connection failed")
-		fixture.mockRepo.On("FindAll", mock.Anything).Return(nil, dbErr)
+		fixture.mockRepo.On("FindAll", mock.Anything, mock.Anything).Return(nil, dbErr)
 
-		allTasks, err := fixture.service.GetAll(fixture.ctx)
-		assert.Nil(t, allTasks)
+		page, err := fixture.service.GetAll(fixture.ctx, &contracts.ListTasks{})
+		assert.Nil(t, page)
 		require.Contains(t, err.Error(), "failed to get tasks: "+dbErr.Error())
 
 		fixture.mockRepo.AssertExpectations(t)
 	})
 }
 
+func TestTaskService_Backpressure(t *testing.T) {
+	setup := func(policy string) *testFixture {
+		fixture := setupFixture()
+		fixture.service = NewTaskService(fixture.mockRepo, fixture.mockAttemptRepo, map[string]chan struct{}{
+			entity.DefaultQueue: fixture.wakeup,
+		}, config.TaskWorker{
+			MaxPending:      2,
+			OverflowPolicy:  policy,
+			OverflowTimeout: 50 * time.Millisecond,
+			PollInterval:    10 * time.Millisecond,
+		}, fixture.bus)
+
+		return fixture
+	}
+
+	createCmd := &contracts.CreateTask{
+		Title:       "Test Task",
+		Description: "Test Description",
+	}
+
+	t.Run("reject returns too many requests when the queue is full", func(t *testing.T) {
+		fixture := setup(config.OverflowReject)
+
+		fixture.mockRepo.On("CountPending", mock.Anything, entity.DefaultQueue).Return(int64(2), nil)
+
+		_, _, err := fixture.service.Create(fixture.ctx, createCmd)
+
+		var appErr *apperror.AppError
+		require.ErrorAs(t, err, &appErr)
+		assert.Equal(t, "TOO_MANY_REQUESTS", appErr.Code)
+		assert.Equal(t, time.Second, appErr.RetryAfter)
+		fixture.mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("reject refuses a whole batch when the queue is full", func(t *testing.T) {
+		fixture := setup(config.OverflowReject)
+
+		fixture.mockRepo.On("CountPending", mock.Anything, entity.DefaultQueue).Return(int64(2), nil)
+
+		_, err := fixture.service.CreateBatch(fixture.ctx, &contracts.CreateTasks{
+			Tasks: []contracts.CreateTask{*createCmd, *createCmd},
+		})
+
+		var appErr *apperror.AppError
+		require.ErrorAs(t, err, &appErr)
+		assert.Equal(t, "TOO_MANY_REQUESTS", appErr.Code)
+		fixture.mockRepo.AssertNotCalled(t, "CreateBatch", mock.Anything, mock.Anything)
+	})
+
+	t.Run("reject refuses a batch that does not fit in the room left", func(t *testing.T) {
+		fixture := setup(config.OverflowReject)
+
+		fixture.mockRepo.On("CountPending", mock.Anything, entity.DefaultQueue).Return(int64(1), nil)
+
+		_, err := fixture.service.CreateBatch(fixture.ctx, &contracts.CreateTasks{
+			Tasks: []contracts.CreateTask{*createCmd, *createCmd},
+		})
+
+		var appErr *apperror.AppError
+		require.ErrorAs(t, err, &appErr)
+		assert.Equal(t, "TOO_MANY_REQUESTS", appErr.Code)
+		fixture.mockRepo.AssertNotCalled(t, "CreateBatch", mock.Anything, mock.Anything)
+	})
+
+	t.Run("batch larger than the limit is a bad request", func(t *testing.T) {
+		fixture := setup(config.OverflowBlock)
+
+		_, err := fixture.service.CreateBatch(fixture.ctx, &contracts.CreateTasks{
+			Tasks: []contracts.CreateTask{*createCmd, *createCmd, *createCmd},
+		})
+
+		var appErr *apperror.AppError
+		require.ErrorAs(t, err, &appErr)
+		assert.Equal(t, "BAD_REQUEST", appErr.Code)
+		fixture.mockRepo.AssertNotCalled(t, "CountPending", mock.Anything, mock.Anything)
+	})
+
+	t.Run("batch is admitted when it fits", func(t *testing.T) {
+		fixture := setup(config.OverflowReject)
+
+		fixture.mockRepo.On("CountPending", mock.Anything, entity.DefaultQueue).Return(int64(0), nil)
+		fixture.mockRepo.On("CreateBatch", mock.Anything, mock.Anything).Return(nil)
+
+		_, err := fixture.service.CreateBatch(fixture.ctx, &contracts.CreateTasks{
+			Tasks: []contracts.CreateTask{*createCmd, *createCmd},
+		})
+		require.NoError(t, err)
+		fixture.mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("block waits until workers make room", func(t *testing.T) {
+		fixture := setup(config.OverflowBlock)
+
+		fixture.mockRepo.On("CountPending", mock.Anything, entity.DefaultQueue).Return(int64(2), nil).Twice()
+		fixture.mockRepo.On("CountPending", mock.Anything, entity.DefaultQueue).Return(int64(1), nil)
+		fixture.mockRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+
+		_, _, err := fixture.service.Create(fixture.ctx, createCmd)
+		require.NoError(t, err)
+
+		fixture.mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("block returns service unavailable after the overflow timeout", func(t *testing.T) {
+		fixture := setup(config.OverflowBlock)
+
+		fixture.mockRepo.On("CountPending", mock.Anything, entity.DefaultQueue).Return(int64(2), nil)
+
+		_, _, err := fixture.service.Create(fixture.ctx, createCmd)
+
+		var appErr *apperror.AppError
+		require.ErrorAs(t, err, &appErr)
+		assert.Equal(t, "SERVICE_UNAVAILABLE", appErr.Code)
+		assert.Positive(t, appErr.RetryAfter)
+		fixture.mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("block honours the request context", func(t *testing.T) {
+		fixture := setup(config.OverflowBlock)
+
+		fixture.mockRepo.On("CountPending", mock.Anything, entity.DefaultQueue).Return(int64(2), nil)
+
+		ctx, cancel := context.WithTimeout(fixture.ctx, 20*time.Millisecond)
+		defer cancel()
+
+		_, _, err := fixture.service.Create(ctx, createCmd)
+
+		var appErr *apperror.AppError
+		require.ErrorAs(t, err, &appErr)
+		assert.Equal(t, "SERVICE_UNAVAILABLE", appErr.Code)
+		assert.Positive(t, appErr.RetryAfter)
+		assert.Contains(t, appErr.Details, context.DeadlineExceeded.Error())
+		fixture.mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("spill stores the task regardless of the backlog", func(t *testing.T) {
+		fixture := setup(config.OverflowSpill)
+
+		fixture.mockRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+
+		_, _, err := fixture.service.Create(fixture.ctx, createCmd)
+		require.NoError(t, err)
+		fixture.mockRepo.AssertNotCalled(t, "CountPending", mock.Anything, mock.Anything)
+	})
+
+	t.Run("delayed tasks are not limited", func(t *testing.T) {
+		fixture := setup(config.OverflowReject)
+
+		fixture.mockRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+
+		_, _, err := fixture.service.Create(fixture.ctx, &contracts.CreateTask{
+			Title:       "Test Task",
+			Description: "Test Description",
+			Delay:       "1m",
+		})
+		require.NoError(t, err)
+		fixture.mockRepo.AssertNotCalled(t, "CountPending", mock.Anything, mock.Anything)
+	})
+}
+
+func TestTaskService_Cancel(t *testing.T) {
+	t.Run("cancel pending or running task", func(t *testing.T) {
+		fixture := setupFixture()
+
+		cancelled := &entity.Task{ID: 1, Status: entity.TaskStatusCancelled}
+		fixture.mockRepo.On("Cancel", mock.Anything, uint64(1)).Return(cancelled, nil)
+		// Tasks blocked on the cancelled one are cancelled as well
+		fixture.mockRepo.On("ResolveDependents", mock.Anything, cancelled).
+			Return([]*entity.Task{{ID: 2, Status: entity.TaskStatusCancelled}}, nil)
+		subscription := fixture.bus.Subscribe(0)
+
+		err := fixture.service.Cancel(fixture.ctx, 1)
+		require.NoError(t, err)
+
+		require.Len(t, subscription.Events(), 2)
+		assert.Equal(t, uint64(1), (<-subscription.Events()).TaskID)
+		assert.Equal(t, uint64(2), (<-subscription.Events()).TaskID)
+
+		fixture.mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("cancel succeeds when its dependents cannot be resolved", func(t *testing.T) {
+		fixture := setupFixture()
+
+		cancelled := &entity.Task{ID: 1, Status: entity.TaskStatusCancelled}
+		fixture.mockRepo.On("Cancel", mock.Anything, uint64(1)).Return(cancelled, nil)
+		// The recovery sweep resolves them later
+		fixture.mockRepo.On("ResolveDependents", mock.Anything, cancelled).
+			Return(nil, errors.New("database connection failed"))
+		subscription := fixture.bus.Subscribe(0)
+
+		err := fixture.service.Cancel(fixture.ctx, 1)
+		require.NoError(t, err)
+
+		require.Len(t, subscription.Events(), 1)
+		assert.Equal(t, uint64(1), (<-subscription.Events()).TaskID)
+
+		fixture.mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("unknown task", func(t *testing.T) {
+		fixture := setupFixture()
+
+		fixture.mockRepo.On("Cancel", mock.Anything, uint64(999)).Return(nil, repository.ErrTaskNotFound)
+
+		err := fixture.service.Cancel(fixture.ctx, 999)
+
+		var appErr *apperror.AppError
+		require.ErrorAs(t, err, &appErr)
+		assert.Equal(t, "NOT_FOUND", appErr.Code)
+	})
+
+	t.Run("finished task cannot be cancelled", func(t *testing.T) {
+		fixture := setupFixture()
+
+		fixture.mockRepo.On("Cancel", mock.Anything, uint64(1)).Return(nil, repository.ErrTaskNotCancellable)
+
+		err := fixture.service.Cancel(fixture.ctx, 1)
+
+		var appErr *apperror.AppError
+		require.ErrorAs(t, err, &appErr)
+		assert.Equal(t, "BAD_REQUEST", appErr.Code)
+	})
+}
+
+func TestTaskService_GetAttempts(t *testing.T) {
+	t.Run("attempts of existing task", func(t *testing.T) {
+		fixture := setupFixture()
+
+		finishedAt := time.Now()
+		expected := []*entity.TaskAttempt{
+			{ID: 1, TaskID: 1, Attempt: 1, Outcome: entity.TaskStatusRetrying, Error: "boom", FinishedAt: &finishedAt},
+			{ID: 2, TaskID: 1, Attempt: 2, Outcome: entity.TaskStatusCompleted, FinishedAt: &finishedAt},
+		}
+
+		fixture.mockRepo.On("FindByID", mock.Anything, uint64(1)).Return(&entity.Task{ID: 1}, nil)
+		fixture.mockAttemptRepo.On("FindByTaskID", mock.Anything, uint64(1)).Return(expected, nil)
+
+		attempts, err := fixture.service.GetAttempts(fixture.ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, expected, attempts)
+
+		fixture.mockRepo.AssertExpectations(t)
+		fixture.mockAttemptRepo.AssertExpectations(t)
+	})
+
+	t.Run("unknown task", func(t *testing.T) {
+		fixture := setupFixture()
+
+		fixture.mockRepo.On("FindByID", mock.Anything, uint64(999)).Return(nil, repository.ErrTaskNotFound)
+
+		attempts, err := fixture.service.GetAttempts(fixture.ctx, 999)
+		assert.Nil(t, attempts)
+
+		var appErr *apperror.AppError
+		require.ErrorAs(t, err, &appErr)
+		assert.Equal(t, "NOT_FOUND", appErr.Code)
+
+		fixture.mockAttemptRepo.AssertNotCalled(t, "FindByTaskID", mock.Anything, mock.Anything)
+	})
+}
+
+func TestTaskService_DeadLetter(t *testing.T) {
+	t.Run("requeue dead-lettered task", func(t *testing.T) {
+		fixture := setupFixture()
+
+		deadLetteredAt := time.Now()
+		task := &entity.Task{
+			ID:             1,
+			Queue:          entity.DefaultQueue,
+			Status:         entity.TaskStatusFailed,
+			Attempts:       3,
+			DeadLetteredAt: &deadLetteredAt,
+		}
+		fixture.mockRepo.On("FindByID", mock.Anything, task.ID).Return(task, nil)
+		requeued := &entity.Task{ID: task.ID, Queue: entity.DefaultQueue, Status: entity.TaskStatusPending}
+		fixture.mockRepo.On("RequeueDeadLettered", mock.Anything, []uint64{task.ID}).Return([]*entity.Task{requeued}, nil)
+		subscription := fixture.bus.Subscribe(0)
+
+		err := fixture.service.Requeue(fixture.ctx, task.ID)
+		require.NoError(t, err)
+		assert.Len(t, fixture.wakeup, 1)
+		assert.Equal(t, entity.TaskStatusPending, task.Status)
+		assert.Zero(t, task.Attempts)
+
+		require.Len(t, subscription.Events(), 1)
+		assert.Equal(t, entity.TaskStatusPending, (<-subscription.Events()).Status)
+
+		fixture.mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("requeue rejects task outside dead-letter queue", func(t *testing.T) {
+		fixture := setupFixture()
+
+		task := &entity.Task{ID: 1, Status: entity.TaskStatusRunning}
+		fixture.mockRepo.On("FindByID", mock.Anything, task.ID).Return(task, nil)
+
+		err := fixture.service.Requeue(fixture.ctx, task.ID)
+
+		var appErr *apperror.AppError
+		require.ErrorAs(t, err, &appErr)
+		assert.Equal(t, "BAD_REQUEST", appErr.Code)
+		fixture.mockRepo.AssertNotCalled(t, "RequeueDeadLettered", mock.Anything, mock.Anything)
+	})
+
+	t.Run("requeue unknown task", func(t *testing.T) {
+		fixture := setupFixture()
+
+		fixture.mockRepo.On("FindByID", mock.Anything, uint64(999)).Return(nil, repository.ErrTaskNotFound)
+
+		err := fixture.service.Requeue(fixture.ctx, 999)
+
+		var appErr *apperror.AppError
+		require.ErrorAs(t, err, &appErr)
+		assert.Equal(t, "NOT_FOUND", appErr.Code)
+	})
+
+	t.Run("bulk requeue", func(t *testing.T) {
+		fixture := setupFixture()
+
+		ids := []uint64{1, 2}
+		fixture.mockRepo.On("RequeueDeadLettered", mock.Anything, ids).Return([]*entity.Task{
+			{ID: 1, Queue: entity.DefaultQueue, Status: entity.TaskStatusPending},
+			{ID: 2, Queue: entity.DefaultQueue, Status: entity.TaskStatusPending},
+		}, nil)
+		subscription := fixture.bus.Subscribe(0)
+
+		count, err := fixture.service.RequeueDeadLettered(fixture.ctx, &contracts.RequeueTasks{IDs: ids})
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), count)
+		assert.Len(t, fixture.wakeup, 1)
+
+		require.Len(t, subscription.Events(), 2)
+		assert.Equal(t, uint64(1), (<-subscription.Events()).TaskID)
+		assert.Equal(t, uint64(2), (<-subscription.Events()).TaskID)
+
+		fixture.mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("purge", func(t *testing.T) {
+		fixture := setupFixture()
+
+		dbErr := errors.New("database connection failed")
+		fixture.mockRepo.On("PurgeDeadLettered", mock.Anything).Return(int64(0), dbErr)
+
+		_, err := fixture.service.PurgeDeadLettered(fixture.ctx)
+		require.ErrorContains(t, err, "failed to purge tasks: "+dbErr.Error())
+
+		fixture.mockRepo.AssertExpectations(t)
+	})
+}
+
 func TestTaskService_ConcurrentCreate(t *testing.T) {
 	t.Run("multiple concurrent task submissions", func(t *testing.T) {
 		fixture := setupFixture(100)
@@ -220,7 +1073,7 @@ func TestTaskService_ConcurrentCreate(t *testing.T) {
 					Description: fmt.Sprintf("Description for task %d", index),
 				}
 
-				if err := fixture.service.Create(fixture.ctx, createCmd); err != nil {
+				if _, _, err := fixture.service.Create(fixture.ctx, createCmd); err != nil {
 					t.Errorf("unexpected error during concurrent creation: %v", err)
 				}
 			}(i)
@@ -228,14 +1081,7 @@ func TestTaskService_ConcurrentCreate(t *testing.T) {
 
 		wg.Wait()
 
-		for i := 0; i < numTasks; i++ {
-			select {
-			case task := <-fixture.taskChannel:
-				assert.Equal(t, entity.TaskStatusPending, task.Status)
-			case <-time.After(5 * time.Second):
-				t.Fatalf("timeout waiting for task %d", i)
-			}
-		}
+		assert.Len(t, fixture.wakeup, numTasks)
 
 		fixture.mockRepo.AssertExpectations(t)
 	})
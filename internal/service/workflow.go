@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"task-pool/config"
+	"task-pool/internal/domain/entity"
+	"task-pool/internal/domain/repository"
+	"task-pool/internal/event"
+	"task-pool/internal/service/contracts"
+	"task-pool/pkg/apperror"
+)
+
+// maxWorkflowTasks bounds the tasks of a workflow across all its steps.
+const maxWorkflowTasks = 1000
+
+type workflowService struct {
+	tasks              *taskService
+	workflowRepository repository.WorkflowRepository
+}
+
+// NewWorkflowService creates a workflow service. The tasks of a workflow are
+// validated and admitted to their queues like the tasks of the task service.
+func NewWorkflowService(
+	workflowRepository repository.WorkflowRepository,
+	taskRepository repository.TaskRepository,
+	wakeup map[string]chan struct{},
+	cfg config.TaskWorker,
+	events event.Publisher,
+) contracts.WorkflowService {
+	return &workflowService{
+		tasks:              newTaskService(taskRepository, wakeup, cfg, events),
+		workflowRepository: workflowRepository,
+	}
+}
+
+func (s *workflowService) Create(ctx context.Context, command *contracts.CreateWorkflow) (*contracts.Workflow, error) {
+	steps := make([][]*entity.Task, len(command.Steps))
+	var fields []apperror.FieldError
+	total := 0
+
+	for i := range command.Steps {
+		for j := range command.Steps[i].Tasks {
+			field := fmt.Sprintf("steps[%d].tasks[%d]", i, j)
+			item := &command.Steps[i].Tasks[j]
+
+			switch {
+			case item.IdempotencyKey != "":
+				fields = append(fields, apperror.FieldError{
+					Field:   field + ".idempotency_key",
+					Message: "idempotency keys are not supported in a workflow",
+				})
+				continue
+			case len(item.DependsOn) > 0:
+				fields = append(fields, apperror.FieldError{
+					Field:   field + ".depends_on",
+					Message: "dependencies are given by the steps of a workflow",
+				})
+				continue
+			}
+
+			task, err := s.tasks.newTask(item)
+			if err != nil {
+				var appErr *apperror.AppError
+				if !errors.As(err, &appErr) {
+					return nil, err
+				}
+
+				fields = append(fields, apperror.FieldError{Field: field, Message: appErr.Message})
+				continue
+			}
+
+			steps[i] = append(steps[i], task)
+			total++
+		}
+	}
+
+	if len(fields) > 0 {
+		return nil, apperror.BadRequest("validation failed").WithFields(fields...)
+	}
+
+	if total > maxWorkflowTasks {
+		return nil, apperror.BadRequest(fmt.Sprintf("a workflow has at most %d tasks", maxWorkflowTasks))
+	}
+
+	// Only the first step is due now, the others are blocked until then. Its
+	// queues must have room for all of its due tasks.
+	var queues []string
+	due := make(map[string]int64)
+	for _, task := range steps[0] {
+		if task.NextRunAt != nil {
+			continue
+		}
+
+		if due[task.Queue] == 0 {
+			queues = append(queues, task.Queue)
+		}
+		due[task.Queue]++
+	}
+
+	for _, queue := range queues {
+		err := s.tasks.admit(ctx, queue, due[queue])
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	workflow := entity.NewWorkflow(command.Name, steps)
+
+	err := s.workflowRepository.Create(ctx, workflow)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create workflow: %w", err)
+	}
+
+	s.tasks.publish(workflow.Tasks...)
+
+	for _, queue := range queues {
+		s.tasks.notify(queue)
+	}
+
+	return workflowView(workflow), nil
+}
+
+func (s *workflowService) GetByID(ctx context.Context, id uint64) (*contracts.Workflow, error) {
+	workflow, err := s.workflowRepository.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrWorkflowNotFound) {
+			return nil, apperror.NotFound("workflow not found")
+		}
+
+		return nil, fmt.Errorf("failed to get workflow: %w", err)
+	}
+
+	return workflowView(workflow), nil
+}
+
+func workflowView(workflow *entity.Workflow) *contracts.Workflow {
+	completed, total := workflow.Progress()
+
+	view := &contracts.Workflow{
+		ID:        workflow.ID,
+		Name:      workflow.Name,
+		Status:    workflow.Status(),
+		Completed: completed,
+		Total:     total,
+		Steps:     workflow.Steps(),
+		CreatedAt: workflow.CreatedAt,
+	}
+	if total > 0 {
+		view.Progress = completed * 100 / total
+	}
+
+	return view
+}
@@ -0,0 +1,200 @@
+package service
+
+import (
+	"context"
+	"task-pool/config"
+	"task-pool/internal/domain/entity"
+	"task-pool/internal/domain/repository"
+	"task-pool/internal/event"
+	"task-pool/internal/service/contracts"
+	"task-pool/pkg/apperror"
+	testmock "task-pool/test/mock"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type workflowFixture struct {
+	mockRepo     *testmock.WorkflowRepository
+	mockTaskRepo *testmock.TaskRepository
+	wakeup       map[string]chan struct{}
+	service      contracts.WorkflowService
+	ctx          context.Context
+}
+
+func setupWorkflowFixture(cfg config.TaskWorker) *workflowFixture {
+	mockRepo := testmock.NewWorkflowRepository()
+	mockTaskRepo := testmock.NewTaskRepository()
+	wakeup := map[string]chan struct{}{
+		entity.DefaultQueue: make(chan struct{}, 1),
+		"emails":            make(chan struct{}, 1),
+	}
+
+	return &workflowFixture{
+		mockRepo:     mockRepo,
+		mockTaskRepo: mockTaskRepo,
+		wakeup:       wakeup,
+		service:      NewWorkflowService(mockRepo, mockTaskRepo, wakeup, cfg, event.NewBus(10)),
+		ctx:          context.Background(),
+	}
+}
+
+func workflowTask(title string) contracts.CreateTask {
+	return contracts.CreateTask{Title: title, Description: "Workflow step", Type: "sleep"}
+}
+
+func TestWorkflowService_Create(t *testing.T) {
+	t.Run("fan-out and fan-in steps", func(t *testing.T) {
+		fixture := setupWorkflowFixture(config.TaskWorker{OverflowPolicy: config.OverflowSpill})
+
+		fanOut := workflowTask("Resize")
+		fanOut.Queue = "emails"
+		command := &contracts.CreateWorkflow{
+			Name: "thumbnails",
+			Steps: []contracts.WorkflowStep{
+				{Tasks: []contracts.CreateTask{workflowTask("Download")}},
+				{Tasks: []contracts.CreateTask{workflowTask("Resize"), fanOut}},
+				{Tasks: []contracts.CreateTask{workflowTask("Upload")}},
+			},
+		}
+
+		fixture.mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(workflow *entity.Workflow) bool {
+			steps := workflow.Steps()
+			return workflow.Name == "thumbnails" &&
+				len(steps) == 3 && len(steps[0]) == 1 && len(steps[1]) == 2 && len(steps[2]) == 1 &&
+				steps[1][1].Queue == "emails" && steps[2][0].WorkflowStep == 2
+		})).Run(func(args mock.Arguments) {
+			workflow := args.Get(1).(*entity.Workflow)
+			workflow.ID = 7
+			for i, task := range workflow.Tasks {
+				task.ID = uint64(i + 1)
+				if task.WorkflowStep > 0 {
+					task.Status = entity.TaskStatusBlocked
+				}
+			}
+		}).Return(nil)
+
+		workflow, err := fixture.service.Create(fixture.ctx, command)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(7), workflow.ID)
+		assert.Equal(t, entity.WorkflowStatusPending, workflow.Status)
+		assert.Equal(t, 4, workflow.Total)
+		assert.Equal(t, 0, workflow.Progress)
+		assert.Len(t, workflow.Steps, 3)
+
+		// Only the queue of the first step has work to do
+		assert.Len(t, fixture.wakeup[entity.DefaultQueue], 1)
+		assert.Empty(t, fixture.wakeup["emails"])
+		fixture.mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("invalid tasks are reported by step and index", func(t *testing.T) {
+		fixture := setupWorkflowFixture(config.TaskWorker{OverflowPolicy: config.OverflowSpill})
+
+		unknownQueue := workflowTask("Notify")
+		unknownQueue.Queue = "sms"
+		withKey := workflowTask("Charge")
+		withKey.IdempotencyKey = "order-1234"
+		withDependency := workflowTask("Ship")
+		withDependency.DependsOn = []uint64{1}
+
+		_, err := fixture.service.Create(fixture.ctx, &contracts.CreateWorkflow{
+			Name: "checkout",
+			Steps: []contracts.WorkflowStep{
+				{Tasks: []contracts.CreateTask{withKey}},
+				{Tasks: []contracts.CreateTask{workflowTask("Pack"), unknownQueue, withDependency}},
+			},
+		})
+
+		var appErr *apperror.AppError
+		require.ErrorAs(t, err, &appErr)
+		assert.Equal(t, "BAD_REQUEST", appErr.Code)
+		assert.Equal(t, []apperror.FieldError{
+			{Field: "steps[0].tasks[0].idempotency_key", Message: "idempotency keys are not supported in a workflow"},
+			{Field: "steps[1].tasks[1]", Message: `unknown queue "sms"`},
+			{Field: "steps[1].tasks[2].depends_on", Message: "dependencies are given by the steps of a workflow"},
+		}, appErr.Fields)
+		fixture.mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("full queue of the first step rejects the workflow", func(t *testing.T) {
+		fixture := setupWorkflowFixture(config.TaskWorker{
+			MaxPending:     1,
+			OverflowPolicy: config.OverflowReject,
+			PollInterval:   time.Second,
+		})
+
+		fixture.mockTaskRepo.On("CountPending", mock.Anything, entity.DefaultQueue).Return(int64(1), nil)
+
+		_, err := fixture.service.Create(fixture.ctx, &contracts.CreateWorkflow{
+			Name:  "report",
+			Steps: []contracts.WorkflowStep{{Tasks: []contracts.CreateTask{workflowTask("Build")}}},
+		})
+
+		var appErr *apperror.AppError
+		require.ErrorAs(t, err, &appErr)
+		assert.Equal(t, "TOO_MANY_REQUESTS", appErr.Code)
+		fixture.mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("first step must fit in its queue as a whole", func(t *testing.T) {
+		fixture := setupWorkflowFixture(config.TaskWorker{
+			MaxPending:     3,
+			OverflowPolicy: config.OverflowReject,
+			PollInterval:   time.Second,
+		})
+
+		fixture.mockTaskRepo.On("CountPending", mock.Anything, entity.DefaultQueue).Return(int64(2), nil)
+
+		_, err := fixture.service.Create(fixture.ctx, &contracts.CreateWorkflow{
+			Name: "thumbnails",
+			Steps: []contracts.WorkflowStep{
+				{Tasks: []contracts.CreateTask{workflowTask("Small"), workflowTask("Large")}},
+			},
+		})
+
+		var appErr *apperror.AppError
+		require.ErrorAs(t, err, &appErr)
+		assert.Equal(t, "TOO_MANY_REQUESTS", appErr.Code)
+		fixture.mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+}
+
+func TestWorkflowService_GetByID(t *testing.T) {
+	t.Run("status and progress are derived from the tasks", func(t *testing.T) {
+		fixture := setupWorkflowFixture(config.TaskWorker{})
+
+		fixture.mockRepo.On("FindByID", mock.Anything, uint64(7)).Return(&entity.Workflow{
+			ID:   7,
+			Name: "thumbnails",
+			Tasks: []*entity.Task{
+				{ID: 1, WorkflowStep: 0, Status: entity.TaskStatusCompleted},
+				{ID: 2, WorkflowStep: 1, Status: entity.TaskStatusCompleted},
+				{ID: 3, WorkflowStep: 1, Status: entity.TaskStatusRunning},
+				{ID: 4, WorkflowStep: 2, Status: entity.TaskStatusBlocked},
+			},
+		}, nil)
+
+		workflow, err := fixture.service.GetByID(fixture.ctx, 7)
+		require.NoError(t, err)
+		assert.Equal(t, entity.WorkflowStatusRunning, workflow.Status)
+		assert.Equal(t, 2, workflow.Completed)
+		assert.Equal(t, 50, workflow.Progress)
+		assert.Len(t, workflow.Steps[1], 2)
+	})
+
+	t.Run("workflow not found", func(t *testing.T) {
+		fixture := setupWorkflowFixture(config.TaskWorker{})
+
+		fixture.mockRepo.On("FindByID", mock.Anything, uint64(999)).Return(nil, repository.ErrWorkflowNotFound)
+
+		_, err := fixture.service.GetByID(fixture.ctx, 999)
+
+		var appErr *apperror.AppError
+		require.ErrorAs(t, err, &appErr)
+		assert.Equal(t, "NOT_FOUND", appErr.Code)
+	})
+}
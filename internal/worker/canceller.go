@@ -0,0 +1,50 @@
+package worker
+
+import (
+	"context"
+	"task-pool/pkg/logger"
+	"time"
+)
+
+// watchCancellations aborts the handlers of in-flight tasks that have been
+// cancelled. Cancellation is recorded in the repository, so the tasks are
+// looked up every poll interval to catch requests served by any replica.
+func (w *taskWorker[T]) watchCancellations(ctx context.Context) {
+	defer w.wg.Done()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(w.pollInterval):
+		}
+
+		w.mu.Lock()
+		ids := make([]uint64, 0, len(w.inflight))
+		for id := range w.inflight {
+			ids = append(ids, id)
+		}
+		w.mu.Unlock()
+
+		if len(ids) == 0 {
+			continue
+		}
+
+		cancelled, err := w.taskRepository.FindCancelled(ctx, ids)
+		if err != nil {
+			if ctx.Err() == nil {
+				logger.Error("Error getting cancelled tasks").WithError(err).Log()
+			}
+			continue
+		}
+
+		w.mu.Lock()
+		for _, id := range cancelled {
+			if cancel, ok := w.inflight[id]; ok {
+				logger.Info("Aborting cancelled task").WithUint64("task_id", id).Log()
+				cancel(ErrCancelled)
+			}
+		}
+		w.mu.Unlock()
+	}
+}
@@ -0,0 +1,28 @@
+package worker
+
+import (
+	"context"
+	"math/rand"
+	"task-pool/internal/domain/entity"
+	"time"
+)
+
+const TaskTypeSleep = "sleep"
+
+// SleepResult is the result of a sleep task.
+type SleepResult struct {
+	Slept string `json:"slept"`
+}
+
+// SleepHandler simulates work by sleeping between 1 and 5 seconds.
+func SleepHandler(ctx context.Context, _ *entity.Task) (any, error) {
+	num := rand.Intn(5) + 1
+	duration := time.Duration(num) * time.Second
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-time.After(duration):
+		return SleepResult{Slept: duration.String()}, nil
+	}
+}
@@ -0,0 +1,117 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"task-pool/internal/domain/entity"
+	"task-pool/internal/domain/repository"
+	"task-pool/internal/event"
+	"task-pool/pkg/logger"
+	"time"
+)
+
+// heartbeat keeps the heartbeat of a task fresh while it is processed, so
+// that no other worker recovers it. A task that has been recovered meanwhile
+// is aborted through abort. It returns the function stopping the heartbeat.
+func (w *taskWorker[T]) heartbeat(id uint64, abort context.CancelCauseFunc) (stop func()) {
+	if w.heartbeatTimeout <= 0 {
+		return func() {}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(w.heartbeatTimeout / 3)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+
+			// The heartbeat outlives shutdown, in-flight tasks are still held.
+			err := w.taskRepository.Heartbeat(context.Background(), id)
+			if errors.Is(err, repository.ErrTaskLost) {
+				logger.Warn("Task no longer held, aborting its handler").WithUint64("task_id", id).Log()
+				abort(err)
+				return
+			}
+			if err != nil {
+				logger.Error("Error sending task heartbeat").WithUint64("task_id", id).WithError(err).Log()
+			}
+		}
+	}()
+
+	return func() { close(done) }
+}
+
+// recoverAbandoned periodically hands back the tasks held by workers that
+// stopped sending heartbeats, e.g. because their process was killed.
+func (w *taskWorker[T]) recoverAbandoned(ctx context.Context) {
+	defer w.wg.Done()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(w.heartbeatTimeout / 3):
+		}
+
+		w.recover(ctx)
+	}
+}
+
+// recover recovers the abandoned tasks once. Tasks to retry wake up the
+// workers of their queue, failed ones resolve their dependents. It then
+// resolves the blocked tasks left behind by dependencies whose dependents
+// could not be resolved when they finished.
+func (w *taskWorker[T]) recover(ctx context.Context) {
+	defer w.resolveBlocked(ctx)
+
+	tasks, err := w.taskRepository.RecoverAbandoned(ctx, w.heartbeatTimeout, w.retryPolicy.MaxAttempts)
+	if err != nil {
+		if ctx.Err() == nil {
+			logger.Error("Error recovering abandoned tasks").WithError(err).Log()
+		}
+		return
+	}
+
+	for _, task := range tasks {
+		logger.Warn("Recovered task of a lost worker").
+			WithUint64("task_id", task.ID).
+			WithString("status", string(task.Status)).
+			Log()
+
+		w.events.Publish(event.NewTaskEvent(event.TypeStatus, task))
+
+		if task.Status == entity.TaskStatusFailed {
+			w.resolveDependents(ctx, task)
+			continue
+		}
+
+		w.wakeup.Notify(task.Queue)
+	}
+}
+
+// resolveBlocked releases or cancels the blocked tasks whose dependencies have
+// already finished.
+func (w *taskWorker[T]) resolveBlocked(ctx context.Context) {
+	resolved, err := w.taskRepository.ResolveBlocked(ctx)
+	if err != nil && ctx.Err() == nil {
+		logger.Error("Error resolving blocked tasks").WithError(err).Log()
+	}
+
+	for _, task := range resolved {
+		logger.Warn("Resolved task of a finished dependency").
+			WithUint64("task_id", task.ID).
+			WithString("status", string(task.Status)).
+			Log()
+
+		w.events.Publish(event.NewTaskEvent(event.TypeStatus, task))
+
+		if task.Status == entity.TaskStatusPending {
+			w.wakeup.Notify(task.Queue)
+		}
+	}
+}
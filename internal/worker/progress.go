@@ -0,0 +1,114 @@
+package worker
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Reporter lets a handler report how far the task it executes has got.
+type Reporter interface {
+	// Report records the percentage of the work done, clamped to 0-100, and
+	// a message describing the current step.
+	Report(percent int, message string)
+}
+
+type progressKey struct{}
+
+// Progress returns the reporter of the task handled with ctx. Outside of a
+// worker the reports are discarded.
+func Progress(ctx context.Context) Reporter {
+	if reporter, ok := ctx.Value(progressKey{}).(Reporter); ok {
+		return reporter
+	}
+
+	return discardProgress{}
+}
+
+func withProgress(ctx context.Context, reporter Reporter) context.Context {
+	return context.WithValue(ctx, progressKey{}, reporter)
+}
+
+type discardProgress struct{}
+
+func (discardProgress) Report(int, string) {}
+
+// progressReporter writes the progress reported by a handler at most once
+// every interval. Reports arriving in between are coalesced, the latest one
+// is written once the interval has passed.
+type progressReporter struct {
+	interval time.Duration
+	write    func(progress int, message string)
+
+	mu       sync.Mutex
+	progress int
+	message  string
+	reported bool
+	stopped  bool
+	last     time.Time
+	timer    *time.Timer
+
+	// writing keeps the writes in the order of the reports.
+	writing sync.Mutex
+}
+
+func newProgressReporter(interval time.Duration, write func(progress int, message string)) *progressReporter {
+	return &progressReporter{interval: interval, write: write}
+}
+
+func (r *progressReporter) Report(percent int, message string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.stopped {
+		return
+	}
+
+	r.progress = min(max(percent, 0), 100)
+	r.message = message
+	r.reported = true
+
+	// The write already scheduled picks up this report.
+	if r.timer != nil {
+		return
+	}
+
+	r.timer = time.AfterFunc(max(r.interval-time.Since(r.last), 0), r.flush)
+}
+
+func (r *progressReporter) flush() {
+	r.writing.Lock()
+	defer r.writing.Unlock()
+
+	r.mu.Lock()
+	if r.stopped {
+		r.mu.Unlock()
+		return
+	}
+
+	progress, message := r.progress, r.message
+	r.timer = nil
+	r.last = time.Now()
+	r.mu.Unlock()
+
+	r.write(progress, message)
+}
+
+// stop ends the reporting once the handler has returned, a write not started
+// yet is dropped and one in progress is waited for, so that no progress is
+// written after the outcome. It returns the last report, ok is false when
+// there was none.
+func (r *progressReporter) stop() (progress int, message string, ok bool) {
+	r.mu.Lock()
+	r.stopped = true
+	if r.timer != nil {
+		r.timer.Stop()
+	}
+	progress, message, ok = r.progress, r.message, r.reported
+	r.mu.Unlock()
+
+	r.writing.Lock()
+	defer r.writing.Unlock()
+
+	return progress, message, ok
+}
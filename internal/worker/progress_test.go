@@ -0,0 +1,119 @@
+package worker
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type progressWrite struct {
+	progress int
+	message  string
+}
+
+func TestProgressReporter(t *testing.T) {
+	newReporter := func(interval time.Duration) (*progressReporter, func() []progressWrite) {
+		var mu sync.Mutex
+		var writes []progressWrite
+
+		reporter := newProgressReporter(interval, func(progress int, message string) {
+			mu.Lock()
+			defer mu.Unlock()
+			writes = append(writes, progressWrite{progress, message})
+		})
+
+		return reporter, func() []progressWrite {
+			mu.Lock()
+			defer mu.Unlock()
+			return append([]progressWrite(nil), writes...)
+		}
+	}
+
+	t.Run("reports within an interval are coalesced", func(t *testing.T) {
+		reporter, writes := newReporter(50 * time.Millisecond)
+
+		for i := 1; i <= 100; i++ {
+			reporter.Report(i, "step")
+		}
+
+		assert.Eventually(t, func() bool {
+			w := writes()
+			return len(w) > 0 && w[len(w)-1] == progressWrite{100, "step"}
+		}, time.Second, 5*time.Millisecond)
+		assert.LessOrEqual(t, len(writes()), 2)
+	})
+
+	t.Run("first report is written right away", func(t *testing.T) {
+		reporter, writes := newReporter(time.Hour)
+
+		reporter.Report(10, "download")
+
+		assert.Eventually(t, func() bool {
+			return len(writes()) == 1
+		}, time.Second, 5*time.Millisecond)
+	})
+
+	t.Run("stop drops the pending write and returns the last report", func(t *testing.T) {
+		reporter, writes := newReporter(time.Hour)
+
+		_, _, ok := reporter.stop()
+		assert.False(t, ok)
+
+		reporter, writes = newReporter(time.Hour)
+		reporter.Report(10, "download")
+		assert.Eventually(t, func() bool { return len(writes()) == 1 }, time.Second, 5*time.Millisecond)
+		reporter.Report(-5, "resize")
+
+		progress, message, ok := reporter.stop()
+		assert.True(t, ok)
+		assert.Equal(t, 0, progress)
+		assert.Equal(t, "resize", message)
+
+		reporter.Report(50, "upload")
+		time.Sleep(20 * time.Millisecond)
+		assert.Equal(t, []progressWrite{{10, "download"}}, writes())
+	})
+
+	t.Run("stop waits for the write in progress", func(t *testing.T) {
+		writing := make(chan struct{})
+		unblock := make(chan struct{})
+		reporter := newProgressReporter(time.Hour, func(progress int, message string) {
+			close(writing)
+			<-unblock
+		})
+
+		reporter.Report(10, "download")
+		<-writing
+
+		stopped := make(chan struct{})
+		go func() {
+			reporter.stop()
+			close(stopped)
+		}()
+
+		select {
+		case <-stopped:
+			t.Fatal("stop returned while a write was in progress")
+		case <-time.After(20 * time.Millisecond):
+		}
+
+		close(unblock)
+		assert.Eventually(t, func() bool {
+			select {
+			case <-stopped:
+				return true
+			default:
+				return false
+			}
+		}, time.Second, 5*time.Millisecond)
+	})
+
+	t.Run("reports outside a worker are discarded", func(t *testing.T) {
+		assert.NotPanics(t, func() {
+			Progress(context.Background()).Report(50, "ignored")
+		})
+	})
+}
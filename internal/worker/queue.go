@@ -0,0 +1,47 @@
+package worker
+
+import (
+	"task-pool/config"
+	"task-pool/internal/domain/entity"
+)
+
+// Wakeup holds a wakeup channel per queue. Sending on the channel of a queue
+// lets an idle worker of that queue claim new work before the next poll.
+type Wakeup map[string]chan struct{}
+
+// NewWakeup creates the wakeup channels of the default queue and of every
+// queue configured in cfg.Queues.
+func NewWakeup(cfg config.TaskWorker) Wakeup {
+	wakeup := make(Wakeup)
+	for queue := range queueWorkers(cfg) {
+		wakeup[queue] = make(chan struct{}, cfg.QueueSize)
+	}
+
+	return wakeup
+}
+
+// Notify wakes up an idle worker of queue without blocking the caller.
+func (w Wakeup) Notify(queue string) {
+	select {
+	case w[queue] <- struct{}{}:
+	default:
+	}
+}
+
+// NotifyAll wakes up an idle worker of every queue.
+func (w Wakeup) NotifyAll() {
+	for queue := range w {
+		w.Notify(queue)
+	}
+}
+
+// queueWorkers returns the worker count of every queue. The default queue
+// runs cfg.Workers workers unless cfg.Queues overrides it.
+func queueWorkers(cfg config.TaskWorker) map[string]int {
+	queues := map[string]int{entity.DefaultQueue: cfg.Workers}
+	for queue, workers := range cfg.Queues {
+		queues[queue] = workers
+	}
+
+	return queues
+}
@@ -0,0 +1,68 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"task-pool/internal/domain/entity"
+)
+
+var (
+	ErrUnknownTaskType = errors.New("unknown task type")
+)
+
+// HandlerFunc executes a single task. A non-nil error marks the task as failed,
+// otherwise the returned value, if any, is stored as the JSON result of the
+// task.
+type HandlerFunc func(ctx context.Context, task *entity.Task) (any, error)
+
+// Registry maps task types to the handlers that execute them.
+type Registry struct {
+	mu       sync.RWMutex
+	handlers map[string]HandlerFunc
+}
+
+func NewRegistry() *Registry {
+	return &Registry{handlers: make(map[string]HandlerFunc)}
+}
+
+// Register binds a handler to a task type, replacing any previous handler.
+func (r *Registry) Register(taskType string, handler HandlerFunc) {
+	if taskType == "" {
+		panic("worker: task type must not be empty")
+	}
+	if handler == nil {
+		panic("worker: nil handler for task type " + taskType)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.handlers[taskType] = handler
+}
+
+// Lookup returns the handler registered for the given task type.
+func (r *Registry) Lookup(taskType string) (HandlerFunc, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	handler, ok := r.handlers[taskType]
+	if !ok {
+		return nil, fmt.Errorf("%w: no handler registered for %q", ErrUnknownTaskType, taskType)
+	}
+
+	return handler, nil
+}
+
+var defaultRegistry = NewRegistry()
+
+// Register binds a handler to a task type on the default registry.
+func Register(taskType string, handler HandlerFunc) {
+	defaultRegistry.Register(taskType, handler)
+}
+
+// DefaultRegistry returns the registry used by package level Register.
+func DefaultRegistry() *Registry {
+	return defaultRegistry
+}
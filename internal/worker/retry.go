@@ -0,0 +1,92 @@
+package worker
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"task-pool/config"
+	"time"
+)
+
+// RetryPolicy decides how often and how late a failed task is retried.
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+	// Jitter is the fraction of the delay added at random, 0.2 adds up to 20%.
+	Jitter float64
+}
+
+func NewRetryPolicy(cfg config.TaskWorker) RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: cfg.MaxAttempts,
+		BaseDelay:   cfg.RetryBaseDelay,
+		MaxDelay:    cfg.RetryMaxDelay,
+		Jitter:      cfg.RetryJitter,
+	}
+}
+
+// Backoff returns the delay before the next run of a task that has already
+// been attempted the given number of times.
+func (p RetryPolicy) Backoff(attempt int) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+
+	delay := float64(p.BaseDelay) * math.Pow(2, float64(attempt-1))
+	if p.MaxDelay > 0 && delay > float64(p.MaxDelay) {
+		delay = float64(p.MaxDelay)
+	}
+
+	if p.Jitter > 0 {
+		delay += rand.Float64() * p.Jitter * delay
+	}
+
+	// Without a max delay a late attempt overflows Duration, and so does
+	// converting a float64 too large for it.
+	if delay >= math.MaxInt64 {
+		return math.MaxInt64
+	}
+
+	return time.Duration(delay)
+}
+
+// ShouldRetry reports whether a task that failed with err on its given attempt
+// gets another run. maxAttempts overrides the policy when greater than zero.
+func (p RetryPolicy) ShouldRetry(err error, attempt, maxAttempts int) bool {
+	if IsPermanent(err) {
+		return false
+	}
+
+	if maxAttempts <= 0 {
+		maxAttempts = p.MaxAttempts
+	}
+
+	return attempt < maxAttempts
+}
+
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+// Permanent marks err as not retryable, the task fails without further attempts.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &permanentError{err: err}
+}
+
+func IsPermanent(err error) bool {
+	var pErr *permanentError
+	return errors.As(err, &pErr)
+}
@@ -0,0 +1,111 @@
+package worker
+
+import (
+	"context"
+	"sync"
+	"task-pool/config"
+	"task-pool/internal/domain/entity"
+	"task-pool/internal/domain/repository"
+	"task-pool/internal/event"
+	"task-pool/pkg/logger"
+	"time"
+)
+
+// ScheduleRunner turns the ticks of recurring schedules into pending tasks.
+// Every replica runs one, the repository makes sure each tick fires once.
+type ScheduleRunner struct {
+	stop               context.CancelFunc
+	pollInterval       time.Duration
+	wakeup             Wakeup
+	scheduleRepository repository.ScheduleRepository
+	events             event.Publisher
+	wg                 sync.WaitGroup
+}
+
+func NewScheduleRunner(
+	scheduleRepository repository.ScheduleRepository,
+	cfg config.TaskWorker,
+	wakeup Wakeup,
+	events event.Publisher,
+) *ScheduleRunner {
+	return &ScheduleRunner{
+		stop:               func() {},
+		pollInterval:       cfg.PollInterval,
+		wakeup:             wakeup,
+		scheduleRepository: scheduleRepository,
+		events:             events,
+	}
+}
+
+func (r *ScheduleRunner) Run(ctx context.Context) {
+	ctx, r.stop = context.WithCancel(ctx)
+
+	r.wg.Add(1)
+	go r.run(ctx)
+}
+
+// Shutdown stops firing schedules and waits for the current tick to finish.
+func (r *ScheduleRunner) Shutdown(ctx context.Context) error {
+	r.stop()
+
+	done := make(chan struct{})
+	go func() {
+		r.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (r *ScheduleRunner) run(ctx context.Context) {
+	defer r.wg.Done()
+
+	for {
+		r.tick(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(r.pollInterval):
+		}
+	}
+}
+
+func (r *ScheduleRunner) tick(ctx context.Context) {
+	tasks, err := r.scheduleRepository.FireDue(ctx, time.Now(), fire)
+	if err != nil {
+		if ctx.Err() == nil {
+			logger.Error("Error firing schedules").WithError(err).Log()
+		}
+		return
+	}
+
+	for _, task := range tasks {
+		logger.Info("Schedule fired").
+			WithUint64("schedule_id", *task.ScheduleID).
+			WithUint64("task_id", task.ID).
+			Log()
+
+		r.events.Publish(event.NewTaskEvent(event.TypeStatus, task))
+		r.wakeup.Notify(task.Queue)
+	}
+}
+
+// fire creates the task of the current tick. A schedule whose cron expression
+// can no longer be parsed, or has no next tick, is disabled instead of
+// failing every tick.
+func fire(schedule *entity.Schedule) *entity.Task {
+	task, err := schedule.Fire(time.Now())
+	if err != nil {
+		logger.Error("Disabling schedule").WithUint64("schedule_id", schedule.ID).WithError(err).Log()
+		schedule.Enabled = false
+		return nil
+	}
+
+	return task
+}
@@ -0,0 +1,87 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"task-pool/config"
+	"task-pool/internal/domain/entity"
+	"task-pool/internal/event"
+	testmock "task-pool/test/mock"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduleRunner_tick(t *testing.T) {
+	t.Run("fires due schedules and wakes workers", func(t *testing.T) {
+		mockRepo := testmock.NewScheduleRepository()
+		wakeup := Wakeup{entity.DefaultQueue: make(chan struct{}, 1)}
+		bus := event.NewBus(1)
+		subscription := bus.Subscribe(0)
+		runner := NewScheduleRunner(mockRepo, config.TaskWorker{PollInterval: time.Second}, wakeup, bus)
+
+		schedule, err := entity.NewSchedule("every-minute", "* * * * *", "Ping", "Ping the service", "ping", nil)
+		require.NoError(t, err)
+		schedule.ID = 7
+		previousRun := schedule.NextRunAt
+
+		fired := make([]*entity.Task, 1)
+		mockRepo.On("FireDue", mock.Anything, mock.Anything, mock.Anything).
+			Run(func(args mock.Arguments) {
+				fire := args.Get(2).(func(*entity.Schedule) *entity.Task)
+				fired[0] = fire(schedule)
+			}).
+			Return(fired, nil)
+
+		runner.tick(context.Background())
+
+		require.NotNil(t, fired[0])
+		assert.Equal(t, "Ping", fired[0].Title)
+		assert.Equal(t, "ping", fired[0].Type)
+		assert.Equal(t, entity.TaskStatusPending, fired[0].Status)
+		assert.Equal(t, schedule.ID, *fired[0].ScheduleID)
+		assert.NotNil(t, schedule.LastRunAt)
+		assert.False(t, schedule.NextRunAt.Before(previousRun))
+		assert.Len(t, wakeup[entity.DefaultQueue], 1)
+
+		require.Len(t, subscription.Events(), 1)
+		created := <-subscription.Events()
+		assert.Equal(t, event.TypeStatus, created.Type)
+		assert.Equal(t, entity.TaskStatusPending, created.Status)
+	})
+
+	t.Run("invalid cron expression disables the schedule", func(t *testing.T) {
+		schedule := &entity.Schedule{ID: 7, Cron: "not a cron", Enabled: true}
+
+		task := fire(schedule)
+
+		assert.Nil(t, task)
+		assert.False(t, schedule.Enabled)
+	})
+
+	t.Run("cron expression that never fires disables the schedule", func(t *testing.T) {
+		schedule := &entity.Schedule{ID: 7, Cron: "0 0 30 2 *", Enabled: true}
+
+		task := fire(schedule)
+
+		assert.Nil(t, task)
+		assert.False(t, schedule.Enabled)
+		assert.Nil(t, schedule.LastRunAt)
+	})
+
+	t.Run("repository error does not wake workers", func(t *testing.T) {
+		mockRepo := testmock.NewScheduleRepository()
+		wakeup := Wakeup{entity.DefaultQueue: make(chan struct{}, 1)}
+		runner := NewScheduleRunner(mockRepo, config.TaskWorker{PollInterval: time.Second}, wakeup, event.NewBus(1))
+
+		mockRepo.On("FireDue", mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("database connection failed"))
+
+		runner.tick(context.Background())
+
+		assert.Empty(t, wakeup[entity.DefaultQueue])
+		mockRepo.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,39 @@
+package worker
+
+import (
+	"context"
+	"task-pool/pkg/logger"
+	"time"
+)
+
+// schedule wakes up the workers as soon as a delayed or retried task becomes
+// due, instead of leaving it to the next poll. The next run time is looked up
+// again every poll interval so tasks created meanwhile are picked up.
+func (w *taskWorker[T]) schedule(ctx context.Context) {
+	defer w.wg.Done()
+
+	for {
+		wait, due := w.pollInterval, false
+
+		nextRunAt, err := w.taskRepository.FindNextRunAt(ctx)
+		if err != nil && ctx.Err() == nil {
+			logger.Error("Error getting next run time").WithError(err).Log()
+		}
+
+		if nextRunAt != nil {
+			if until := time.Until(*nextRunAt); until < wait {
+				wait, due = max(until, 0), true
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+
+		if due {
+			w.wakeup.NotifyAll()
+		}
+	}
+}
@@ -2,74 +2,516 @@ package worker
 
 import (
 	"context"
-	"math/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
 	"sync"
+	"task-pool/config"
 	"task-pool/internal/domain/entity"
 	"task-pool/internal/domain/repository"
+	"task-pool/internal/event"
 	"task-pool/pkg/logger"
 	"time"
 )
 
+// abortGrace is how long Shutdown waits for aborted handlers to return before
+// it gives up on them.
+const abortGrace = 5 * time.Second
+
+var (
+	ErrShutdown  = errors.New("worker is shutting down")
+	ErrCancelled = errors.New("task was cancelled")
+	ErrTimeout   = errors.New("task timed out")
+)
+
 type taskWorker[T any] struct {
-	ctx            context.Context
-	workersCount   uint64
-	taskChannel    chan *entity.Task
+	// stop cancels the intake context, workers stop claiming new tasks.
+	stop context.CancelFunc
+	// execCtx is the parent of every handler context, it is only aborted
+	// when in-flight tasks do not finish within the shutdown deadline.
+	execCtx        context.Context
+	abort          context.CancelCauseFunc
+	queues         map[string]int
+	pollInterval   time.Duration
+	priorityAging  time.Duration
+	timeout        time.Duration
+	retryPolicy    RetryPolicy
+	wakeup         Wakeup
 	taskRepository repository.TaskRepository
-	wg             sync.WaitGroup
+	registry       *Registry
+
+	// id identifies this process in the attempt history, every worker
+	// goroutine appends its queue and index.
+	id                string
+	attemptRepository repository.TaskAttemptRepository
+	wg                sync.WaitGroup
+
+	// progressInterval throttles the progress writes of every task.
+	progressInterval time.Duration
+
+	// heartbeatTimeout is how long the tasks of a lost worker stay held
+	// before they are recovered, 0 disables heartbeats.
+	heartbeatTimeout time.Duration
+
+	// abortGrace bounds the wait for aborted handlers on shutdown.
+	abortGrace time.Duration
+
+	// events receives every change the worker makes to a task.
+	events event.Publisher
+
+	// inflight holds the cancel function of every task being handled.
+	mu       sync.Mutex
+	inflight map[uint64]context.CancelCauseFunc
 }
 
+// NewTaskWorker creates a worker pool that claims pending tasks from the
+// repository, with a separate set of workers for every queue. Workers poll
+// every cfg.PollInterval and can be woken up earlier through wakeup. Every
+// execution is recorded in attemptRepository and every change of a task is
+// published to events.
 func NewTaskWorker(
 	taskRepository repository.TaskRepository,
-	workersCount uint64,
-	taskChannel chan *entity.Task,
+	attemptRepository repository.TaskAttemptRepository,
+	registry *Registry,
+	cfg config.TaskWorker,
+	wakeup Wakeup,
+	events event.Publisher,
 ) Worker[*entity.Task] {
+	execCtx, abort := context.WithCancelCause(context.Background())
+
 	return &taskWorker[*entity.Task]{
-		workersCount:   workersCount,
-		taskChannel:    taskChannel,
+		stop:           func() {},
+		execCtx:        execCtx,
+		abort:          abort,
+		queues:         queueWorkers(cfg),
+		pollInterval:   cfg.PollInterval,
+		priorityAging:  cfg.PriorityAging,
+		timeout:        cfg.Timeout,
+		retryPolicy:    NewRetryPolicy(cfg),
+		wakeup:         wakeup,
 		taskRepository: taskRepository,
+		registry:       registry,
 		wg:             sync.WaitGroup{},
+		inflight:       make(map[uint64]context.CancelCauseFunc),
+
+		id:                processID(),
+		attemptRepository: attemptRepository,
+
+		progressInterval: cfg.ProgressInterval,
+
+		heartbeatTimeout: cfg.HeartbeatTimeout,
+
+		abortGrace: abortGrace,
+
+		events: events,
 	}
 }
 
+// processID returns hostname-pid, it tells apart the processes sharing the
+// repository.
+func processID() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+
+	return fmt.Sprintf("%s-%d", hostname, os.Getpid())
+}
+
 func (w *taskWorker[T]) Run(ctx context.Context) {
-	w.ctx = ctx
-	for i := 0; i < int(w.workersCount); i++ {
+	ctx, w.stop = context.WithCancel(ctx)
+	for queue, workers := range w.queues {
+		for i := 0; i < workers; i++ {
+			w.wg.Add(1)
+			go w.wroker(ctx, queue, fmt.Sprintf("%s/%s-%d", w.id, queue, i))
+		}
+	}
+
+	w.wg.Add(1)
+	go w.schedule(ctx)
+
+	w.wg.Add(1)
+	go w.watchCancellations(ctx)
+
+	if w.heartbeatTimeout > 0 {
 		w.wg.Add(1)
-		go w.wroker(ctx)
+		go w.recoverAbandoned(ctx)
 	}
 }
 
-func (w *taskWorker[T]) Shutdown() {}
+// Shutdown stops claiming new tasks and waits for in-flight tasks to finish.
+// When ctx is done first, running handlers get their context cancelled and
+// their tasks are returned to pending. Handlers that still have not returned
+// after a short grace period are left behind, their tasks are returned to
+// pending directly in the repository. Shutdown then returns the context error.
+func (w *taskWorker[T]) Shutdown(ctx context.Context) error {
+	w.stop()
+
+	done := make(chan struct{})
+	go func() {
+		w.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+	}
+
+	logger.Warn("Shutdown deadline reached, aborting in-flight tasks").Log()
+	w.abort(ErrShutdown)
+
+	select {
+	case <-done:
+	case <-time.After(w.abortGrace):
+		w.releaseInflight()
+	}
+
+	return fmt.Errorf("in-flight tasks aborted: %w", ctx.Err())
+}
+
+// releaseInflight returns the tasks whose handlers ignored the abort to
+// pending, the process exits without waiting for them.
+func (w *taskWorker[T]) releaseInflight() {
+	w.mu.Lock()
+	ids := make([]uint64, 0, len(w.inflight))
+	for id := range w.inflight {
+		ids = append(ids, id)
+	}
+	w.mu.Unlock()
+
+	logger.Warn("Handlers did not return after abort, releasing their tasks").WithInt("tasks", len(ids)).Log()
+
+	ctx, cancel := context.WithTimeout(context.Background(), w.abortGrace)
+	defer cancel()
+
+	tasks, err := w.taskRepository.Release(ctx, ids)
+	if err != nil {
+		logger.Error("Error releasing in-flight tasks").WithError(err).Log()
+		return
+	}
+
+	for _, task := range tasks {
+		w.events.Publish(event.NewTaskEvent(event.TypeStatus, task))
+	}
+}
+
+func (w *taskWorker[T]) wroker(ctx context.Context, queue, workerID string) {
+	defer w.wg.Done()
 
-func (w *taskWorker[T]) wroker(ctx context.Context) {
 	for {
+		if ctx.Err() != nil {
+			return
+		}
+
+		task, err := w.taskRepository.ClaimNext(ctx, queue, w.priorityAging)
+		if err == nil {
+			// There may be more work queued, let an idle worker look as well.
+			w.wakeup.Notify(queue)
+			w.process(task, workerID)
+			continue
+		}
+
+		if !errors.Is(err, repository.ErrNoTaskAvailable) && ctx.Err() == nil {
+			logger.Error("Error claiming task").WithString("queue", queue).WithError(err).Log()
+		}
+
 		select {
 		case <-ctx.Done():
-			w.wg.Done()
 			return
-		case task := <-w.taskChannel:
-			w.handle(ctx, task)
+		case <-w.wakeup[queue]:
+		case <-time.After(w.pollInterval):
 		}
 	}
 }
 
-func (w *taskWorker[T]) handle(ctx context.Context, command *entity.Task) {
+// process handles the task with its own context, registered as in-flight so
+// that cancelling the task aborts the handler.
+func (w *taskWorker[T]) process(task *entity.Task, workerID string) {
+	ctx, cancel := context.WithCancelCause(w.execCtx)
+
+	w.mu.Lock()
+	w.inflight[task.ID] = cancel
+	w.mu.Unlock()
+
+	defer func() {
+		w.mu.Lock()
+		delete(w.inflight, task.ID)
+		w.mu.Unlock()
+
+		cancel(nil)
+	}()
+
+	defer w.heartbeat(task.ID, cancel)()
+
+	w.handle(ctx, workerID, task)
+}
+
+func (w *taskWorker[T]) handle(ctx context.Context, workerID string, command *entity.Task) {
 	logger.Info("Starting task processing").
 		WithUint64("task_id", command.ID).
 		WithString("task_title", command.Title).
+		WithString("task_type", command.Type).
+		WithString("queue", command.Queue).
 		Log()
 
-	num := rand.Intn(5) + 1
-	duration := time.Duration(num) * time.Second
+	// Every write is conditional on the worker still holding this attempt.
+	claimed := command.Attempts
+
+	if !w.start(ctx, command, claimed) {
+		return
+	}
+
+	attempt := w.startAttempt(ctx, workerID, command)
+
+	reporter := w.progressReporter(ctx, command)
+	result, err := w.executeWithTimeout(withProgress(ctx, reporter), command)
+
+	// The last report is saved with the outcome of the attempt.
+	if progress, message, ok := reporter.stop(); ok {
+		command.ReportProgress(progress, message)
+	}
+
+	// The attempt records the status the task ends up with, unless the task
+	// was taken away and the attempt closed by recovery.
+	defer func() { w.finishAttempt(ctx, attempt, command, err) }()
+
+	// A cancelled task stays cancelled whatever the handler returned.
+	cancelled := errors.Is(context.Cause(ctx), ErrCancelled)
+	released := err != nil && !cancelled && errors.Is(context.Cause(ctx), ErrShutdown)
+	retry := err != nil && !cancelled && !released && w.retryPolicy.ShouldRetry(err, command.Attempts, command.MaxAttempts)
 
-	time.Sleep(duration)
+	// The task as it was running, in case the outcome cannot be saved.
+	running := *command
+
+	var tErr error
+	switch {
+	case cancelled:
+		tErr = command.Cancel()
+	case err == nil:
+		tErr = command.Complete(result)
+	case released:
+		tErr = command.Release()
+	case retry:
+		tErr = command.Retry(err, time.Now().Add(w.retryPolicy.Backoff(command.Attempts)))
+	case errors.Is(err, ErrTimeout):
+		tErr = command.TimedOut(err)
+	default:
+		tErr = command.Failed(err)
+	}
+	if tErr != nil {
+		logger.Error("Error recording task outcome").WithUint64("task_id", command.ID).WithError(tErr).Log()
+		return
+	}
+
+	// The outcome is persisted even when ctx has been aborted.
+	uErr := w.taskRepository.Update(context.WithoutCancel(ctx), command, claimed)
+	if errors.Is(uErr, repository.ErrTaskLost) {
+		// Recovered while the heartbeat lapsed, the task now belongs to
+		// whoever retries it or to the dead-letter queue.
+		*command = running
+		attempt = nil
+		logger.Warn("Task was recovered from this worker, outcome dropped").WithUint64("task_id", command.ID).Log()
+		return
+	}
+	if errors.Is(uErr, repository.ErrTaskCancelled) {
+		// Cancelled while the handler was finishing, the cancellation has
+		// already been published and handed down to the dependents.
+		*command = running
+		_ = command.Cancel()
+		logger.Warn("Task cancelled").WithUint64("task_id", command.ID).Log()
+		return
+	}
+	if uErr != nil {
+		logger.Error("Error updating task").WithUint64("task_id", command.ID).WithError(uErr).Log()
+		return
+	}
+
+	w.events.Publish(event.NewTaskEvent(event.TypeStatus, command))
+	w.resolveDependents(ctx, command)
+
+	if cancelled {
+		logger.Warn("Task cancelled").WithUint64("task_id", command.ID).Log()
+		return
+	}
+
+	if released {
+		logger.Warn("Task aborted by shutdown, returned to pending").WithUint64("task_id", command.ID).Log()
+		return
+	}
+
+	if retry {
+		logger.Warn("Task failed, retry scheduled").
+			WithUint64("task_id", command.ID).
+			WithInt("attempt", command.Attempts).
+			WithString("next_run_at", command.NextRunAt.Format(time.RFC3339)).
+			WithError(err).
+			Log()
+		return
+	}
 
-	command.Complete()
-	err := w.taskRepository.Update(ctx, command)
 	if err != nil {
-		logger.Error("Error creating task").WithError(err).Log()
+		logger.Error("Task failed").WithUint64("task_id", command.ID).WithInt("attempt", command.Attempts).WithError(err).Log()
 		return
 	}
 
 	logger.Info("Task completed successfully").WithUint64("task_id", command.ID).Log()
 }
+
+// start persists the task as running before it is executed. A task that
+// cannot be marked as running is returned to pending and not executed, one
+// cancelled or recovered since it was claimed is not executed either.
+func (w *taskWorker[T]) start(ctx context.Context, command *entity.Task, claimed int) bool {
+	err := command.Start(time.Now())
+	if err != nil {
+		logger.Error("Error starting task").WithUint64("task_id", command.ID).WithError(err).Log()
+		return false
+	}
+
+	err = w.taskRepository.Update(context.WithoutCancel(ctx), command, claimed)
+	if err == nil {
+		w.events.Publish(event.NewTaskEvent(event.TypeStatus, command))
+		return true
+	}
+
+	if errors.Is(err, repository.ErrTaskCancelled) {
+		_ = command.Cancel()
+		logger.Warn("Task cancelled before it started").WithUint64("task_id", command.ID).Log()
+		return false
+	}
+
+	if errors.Is(err, repository.ErrTaskLost) {
+		logger.Warn("Task was recovered from this worker before it started").WithUint64("task_id", command.ID).Log()
+		return false
+	}
+
+	logger.Error("Error marking task as running").WithUint64("task_id", command.ID).WithError(err).Log()
+
+	if command.Release() == nil {
+		err = w.taskRepository.Update(context.WithoutCancel(ctx), command, claimed)
+		if err != nil {
+			logger.Error("Error releasing task").WithUint64("task_id", command.ID).WithError(err).Log()
+		}
+	}
+
+	return false
+}
+
+// progressReporter returns the reporter passed to the handler of the task,
+// it saves and publishes the reported progress while the task is running.
+// The event is built from a copy taken before the handler runs, the command
+// itself is only touched by the worker goroutine.
+func (w *taskWorker[T]) progressReporter(ctx context.Context, command *entity.Task) *progressReporter {
+	running := *command
+
+	return newProgressReporter(w.progressInterval, func(progress int, message string) {
+		err := w.taskRepository.UpdateProgress(context.WithoutCancel(ctx), running.ID, progress, message)
+		if err != nil {
+			logger.Error("Error updating task progress").WithUint64("task_id", running.ID).WithError(err).Log()
+			return
+		}
+
+		running.ReportProgress(progress, message)
+		w.events.Publish(event.NewTaskEvent(event.TypeProgress, &running))
+	})
+}
+
+// resolveDependents releases or cancels the tasks waiting for the task once
+// it has reached a final status, and wakes up the workers of released tasks.
+func (w *taskWorker[T]) resolveDependents(ctx context.Context, command *entity.Task) {
+	resolved, err := w.taskRepository.ResolveDependents(context.WithoutCancel(ctx), command)
+	if err != nil {
+		logger.Error("Error resolving dependent tasks").WithUint64("task_id", command.ID).WithError(err).Log()
+		return
+	}
+
+	for _, task := range resolved {
+		w.events.Publish(event.NewTaskEvent(event.TypeStatus, task))
+
+		if task.Status == entity.TaskStatusPending {
+			w.wakeup.Notify(task.Queue)
+		}
+	}
+}
+
+// startAttempt records the execution that is about to begin. Failing to record
+// it does not prevent the task from running, nil is returned instead.
+func (w *taskWorker[T]) startAttempt(ctx context.Context, workerID string, command *entity.Task) *entity.TaskAttempt {
+	attempt := entity.NewTaskAttempt(command, workerID, *command.StartedAt)
+
+	err := w.attemptRepository.Create(context.WithoutCancel(ctx), attempt)
+	if err != nil {
+		logger.Error("Error recording task attempt").WithUint64("task_id", command.ID).WithError(err).Log()
+		return nil
+	}
+
+	return attempt
+}
+
+// finishAttempt records the outcome of an attempt started by startAttempt.
+func (w *taskWorker[T]) finishAttempt(ctx context.Context, attempt *entity.TaskAttempt, command *entity.Task, err error) {
+	if attempt == nil {
+		return
+	}
+
+	attempt.Finish(command.Status, err, time.Now())
+
+	uErr := w.attemptRepository.Update(context.WithoutCancel(ctx), attempt)
+	if uErr != nil {
+		logger.Error("Error updating task attempt").WithUint64("task_id", command.ID).WithError(uErr).Log()
+	}
+}
+
+// executeWithTimeout runs the task under its execution timeout. A handler
+// still running at the deadline has its context cancelled and the execution
+// fails with ErrTimeout, whatever the handler eventually returns, so that the
+// retry policy applies.
+func (w *taskWorker[T]) executeWithTimeout(ctx context.Context, command *entity.Task) (json.RawMessage, error) {
+	timeout := w.timeout
+	if command.Timeout > 0 {
+		timeout = time.Duration(command.Timeout)
+	}
+
+	if timeout <= 0 {
+		return w.execute(ctx, command)
+	}
+
+	ctx, cancel := context.WithTimeoutCause(ctx, timeout, ErrTimeout)
+	defer cancel()
+
+	result, err := w.execute(ctx, command)
+	if errors.Is(context.Cause(ctx), ErrTimeout) {
+		return nil, fmt.Errorf("%w after %s", ErrTimeout, timeout)
+	}
+
+	return result, err
+}
+
+// execute dispatches the task to the handler registered for its type and
+// encodes its result, turning a handler panic into an error.
+func (w *taskWorker[T]) execute(ctx context.Context, command *entity.Task) (result json.RawMessage, err error) {
+	handler, err := w.registry.Lookup(command.Type)
+	if err != nil {
+		return nil, Permanent(err)
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			result, err = nil, fmt.Errorf("task handler panicked: %v", r)
+		}
+	}()
+
+	value, err := handler(ctx, command)
+	if err != nil || value == nil {
+		return nil, err
+	}
+
+	result, err = json.Marshal(value)
+	if err != nil {
+		return nil, Permanent(fmt.Errorf("failed to encode task result: %w", err))
+	}
+
+	return result, nil
+}
@@ -3,48 +3,87 @@ package worker
 import (
 	"context"
 	"errors"
+	"math"
+	"slices"
+	"sync"
 	"task-pool/config"
 	"task-pool/internal/domain/entity"
+	"task-pool/internal/domain/repository"
+	"task-pool/internal/event"
 	testmock "task-pool/test/mock"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 // testFixture contains all test dependencies
 type testFixture struct {
-	mockRepo    *testmock.TaskRepository
-	taskChannel chan *entity.Task
-	cfg         config.Config
-	task        *entity.Task
-	ctx         context.Context
-	worker      *taskWorker[*entity.Task]
+	mockRepo        *testmock.TaskRepository
+	mockAttemptRepo *testmock.TaskAttemptRepository
+	wakeup          Wakeup
+	bus             *event.Bus
+	cfg             config.Config
+	registry        *Registry
+	task            *entity.Task
+	ctx             context.Context
+	worker          *taskWorker[*entity.Task]
 }
 
+const (
+	testTaskType = "test"
+	testWorkerID = "test-worker"
+)
+
 // setupFixture creates a simple test fixture with default values
 func setupFixture() *testFixture {
 	f := &testFixture{
-		mockRepo:    testmock.NewTaskRepository(),
-		taskChannel: make(chan *entity.Task, 10),
+		mockRepo:        testmock.NewTaskRepository(),
+		mockAttemptRepo: testmock.NewTaskAttemptRepository(),
+		wakeup:          Wakeup{entity.DefaultQueue: make(chan struct{}, 10)},
+		bus:             event.NewBus(10),
+		registry:        NewRegistry(),
 		cfg: config.Config{
 			TaskWorker: config.TaskWorker{
-				Workers: 1,
+				Workers:        1,
+				PollInterval:   10 * time.Millisecond,
+				MaxAttempts:    3,
+				RetryBaseDelay: time.Second,
+				RetryMaxDelay:  time.Minute,
+				PriorityAging:  time.Minute,
 			},
 		},
 		task: &entity.Task{
 			ID:          1,
 			Title:       "Test Task",
 			Description: "Test Description",
-			Status:      entity.TaskStatusPending,
+			Type:        testTaskType,
+			Queue:       entity.DefaultQueue,
+			Status:      entity.TaskStatusQueued,
+			Attempts:    1,
 		},
 		ctx: context.Background(),
 	}
-	
+
+	f.registry.Register(testTaskType, func(ctx context.Context, task *entity.Task) (any, error) {
+		return nil, nil
+	})
+	f.mockRepo.On("FindNextRunAt", mock.Anything).Return(nil, nil).Maybe()
+	f.mockRepo.On("FindCancelled", mock.Anything, mock.Anything).Return(nil, nil).Maybe()
+	// Every claimed task is persisted as running before it is executed
+	f.mockRepo.On("Update", mock.Anything, mock.MatchedBy(func(task *entity.Task) bool {
+		return task.Status == entity.TaskStatusRunning
+	}), mock.Anything).Return(nil).Maybe()
+	f.mockRepo.On("ResolveDependents", mock.Anything, mock.Anything).Return(nil, nil).Maybe()
+	f.mockRepo.On("UpdateProgress", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+	f.mockAttemptRepo.On("Create", mock.Anything, mock.Anything).Return(nil).Maybe()
+	f.mockAttemptRepo.On("Update", mock.Anything, mock.Anything).Return(nil).Maybe()
+
 	// Create worker
-	f.worker = NewTaskWorker(f.mockRepo, uint64(f.cfg.TaskWorker.Workers), f.taskChannel).(*taskWorker[*entity.Task])
-	
+	f.worker = NewTaskWorker(f.mockRepo, f.mockAttemptRepo, f.registry, f.cfg.TaskWorker, f.wakeup, f.bus).(*taskWorker[*entity.Task])
+
 	return f
 }
 
@@ -55,11 +94,126 @@ func TestTaskWorker_Handle(t *testing.T) {
 		f.mockRepo.On("Update", mock.Anything, mock.MatchedBy(func(updatedTask *entity.Task) bool {
 			return updatedTask.ID == f.task.ID &&
 				updatedTask.Status == entity.TaskStatusCompleted
-		})).Return(nil)
+		}), mock.Anything).Return(nil)
+
+		f.worker.handle(f.ctx, testWorkerID, f.task)
+
+		assert.Equal(t, entity.TaskStatusCompleted, f.task.Status)
+		f.mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("task is persisted as running before it executes", func(t *testing.T) {
+		f := setupFixture()
+
+		var statusAtStart entity.TaskStatus
+		f.registry.Register(testTaskType, func(ctx context.Context, task *entity.Task) (any, error) {
+			statusAtStart = task.Status
+			return nil, nil
+		})
+		f.mockRepo.ExpectedCalls = nil
+		f.mockRepo.On("Update", mock.Anything, mock.MatchedBy(func(task *entity.Task) bool {
+			return task.Status == entity.TaskStatusRunning && task.StartedAt != nil
+		}), mock.Anything).Return(nil).Once()
+		f.mockRepo.On("Update", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+		f.mockRepo.On("ResolveDependents", mock.Anything, mock.Anything).Return(nil, nil).Once()
+
+		f.worker.handle(f.ctx, testWorkerID, f.task)
+
+		assert.Equal(t, entity.TaskStatusRunning, statusAtStart)
+		assert.Equal(t, entity.TaskStatusCompleted, f.task.Status)
+		f.mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("task cancelled before it starts is not executed", func(t *testing.T) {
+		f := setupFixture()
+
+		called := false
+		f.registry.Register(testTaskType, func(ctx context.Context, task *entity.Task) (any, error) {
+			called = true
+			return nil, nil
+		})
+		f.mockRepo.ExpectedCalls = nil
+		f.mockRepo.On("Update", mock.Anything, mock.Anything, mock.Anything).Return(repository.ErrTaskCancelled).Once()
+		subscription := f.bus.Subscribe(f.task.ID)
+
+		f.worker.handle(f.ctx, testWorkerID, f.task)
+
+		assert.False(t, called)
+		assert.Equal(t, entity.TaskStatusCancelled, f.task.Status)
+		assert.Empty(t, subscription.Events())
+		f.mockAttemptRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		f.mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("task is released when running cannot be persisted", func(t *testing.T) {
+		f := setupFixture()
+
+		called := false
+		f.registry.Register(testTaskType, func(ctx context.Context, task *entity.Task) (any, error) {
+			called = true
+			return nil, nil
+		})
+		f.mockRepo.ExpectedCalls = nil
+		f.mockRepo.On("Update", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("database connection failed")).Once()
+		f.mockRepo.On("Update", mock.Anything, mock.MatchedBy(func(task *entity.Task) bool {
+			return task.Status == entity.TaskStatusPending
+		}), mock.Anything).Return(nil).Once()
 
-		f.worker.handle(f.ctx, f.task)
+		f.worker.handle(f.ctx, testWorkerID, f.task)
+
+		assert.False(t, called)
+		assert.Equal(t, entity.TaskStatusPending, f.task.Status)
+		assert.Equal(t, 0, f.task.Attempts)
+		f.mockRepo.AssertExpectations(t)
+	})
 
+	t.Run("finished task is not executed again", func(t *testing.T) {
+		f := setupFixture()
+		f.task.Status = entity.TaskStatusCompleted
+
+		called := false
+		f.registry.Register(testTaskType, func(ctx context.Context, task *entity.Task) (any, error) {
+			called = true
+			return nil, nil
+		})
+
+		f.worker.handle(f.ctx, testWorkerID, f.task)
+
+		assert.False(t, called)
 		assert.Equal(t, entity.TaskStatusCompleted, f.task.Status)
+		f.mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("handler result is stored on the task", func(t *testing.T) {
+		f := setupFixture()
+
+		f.registry.Register(testTaskType, func(ctx context.Context, task *entity.Task) (any, error) {
+			return map[string]int{"sent": 2}, nil
+		})
+		f.mockRepo.On("Update", mock.Anything, mock.MatchedBy(func(updatedTask *entity.Task) bool {
+			return updatedTask.Status == entity.TaskStatusCompleted
+		}), mock.Anything).Return(nil)
+
+		f.worker.handle(f.ctx, testWorkerID, f.task)
+
+		assert.JSONEq(t, `{"sent":2}`, string(f.task.Result))
+		assert.Empty(t, f.task.Error)
+		f.mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("unencodable result fails the task", func(t *testing.T) {
+		f := setupFixture()
+
+		f.registry.Register(testTaskType, func(ctx context.Context, task *entity.Task) (any, error) {
+			return make(chan int), nil
+		})
+		f.mockRepo.On("Update", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+		f.worker.handle(f.ctx, testWorkerID, f.task)
+
+		assert.Equal(t, entity.TaskStatusFailed, f.task.Status)
+		assert.Contains(t, f.task.Error, "failed to encode task result")
+		assert.Nil(t, f.task.Result)
 		f.mockRepo.AssertExpectations(t)
 	})
 
@@ -67,78 +221,900 @@ func TestTaskWorker_Handle(t *testing.T) {
 		f := setupFixture()
 
 		expectedError := errors.New("database connection failed")
-		f.mockRepo.On("Update", mock.Anything, mock.Anything).Return(expectedError)
+		f.mockRepo.On("Update", mock.Anything, mock.Anything, mock.Anything).Return(expectedError)
 
-		f.worker.handle(f.ctx, f.task)
+		f.worker.handle(f.ctx, testWorkerID, f.task)
 
 		// Task should still be completed even if update fails
 		assert.Equal(t, entity.TaskStatusCompleted, f.task.Status)
 		f.mockRepo.AssertExpectations(t)
 	})
+
+	t.Run("handler error schedules a retry", func(t *testing.T) {
+		f := setupFixture()
+
+		f.registry.Register(testTaskType, func(ctx context.Context, task *entity.Task) (any, error) {
+			return nil, errors.New("smtp unavailable")
+		})
+		f.mockRepo.On("Update", mock.Anything, mock.MatchedBy(func(updatedTask *entity.Task) bool {
+			return updatedTask.Status == entity.TaskStatusRetrying
+		}), mock.Anything).Return(nil)
+
+		before := time.Now()
+		f.worker.handle(f.ctx, testWorkerID, f.task)
+
+		assert.Equal(t, entity.TaskStatusRetrying, f.task.Status)
+		assert.Equal(t, "smtp unavailable", f.task.Error)
+		assert.False(t, f.task.IsDeadLettered())
+		if assert.NotNil(t, f.task.NextRunAt) {
+			assert.WithinRange(t, *f.task.NextRunAt, before.Add(time.Second), time.Now().Add(time.Second))
+		}
+		f.mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("per task max attempts overrides policy", func(t *testing.T) {
+		f := setupFixture()
+		f.task.MaxAttempts = 1
+
+		f.registry.Register(testTaskType, func(ctx context.Context, task *entity.Task) (any, error) {
+			return nil, errors.New("smtp unavailable")
+		})
+		f.mockRepo.On("Update", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+		f.worker.handle(f.ctx, testWorkerID, f.task)
+
+		assert.Equal(t, entity.TaskStatusFailed, f.task.Status)
+		f.mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("permanent error is not retried", func(t *testing.T) {
+		f := setupFixture()
+
+		f.registry.Register(testTaskType, func(ctx context.Context, task *entity.Task) (any, error) {
+			return nil, Permanent(errors.New("invalid recipient"))
+		})
+		f.mockRepo.On("Update", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+		f.worker.handle(f.ctx, testWorkerID, f.task)
+
+		assert.Equal(t, entity.TaskStatusFailed, f.task.Status)
+		assert.Equal(t, "invalid recipient", f.task.Error)
+		f.mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("handler error marks task as failed once attempts are exhausted", func(t *testing.T) {
+		f := setupFixture()
+		f.task.Attempts = 3
+
+		f.registry.Register(testTaskType, func(ctx context.Context, task *entity.Task) (any, error) {
+			return nil, errors.New("smtp unavailable")
+		})
+		f.mockRepo.On("Update", mock.Anything, mock.MatchedBy(func(updatedTask *entity.Task) bool {
+			return updatedTask.Status == entity.TaskStatusFailed
+		}), mock.Anything).Return(nil)
+
+		f.worker.handle(f.ctx, testWorkerID, f.task)
+
+		assert.Equal(t, entity.TaskStatusFailed, f.task.Status)
+		assert.Equal(t, "smtp unavailable", f.task.Error)
+		assert.True(t, f.task.IsDeadLettered())
+		f.mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("handler panic marks task as failed", func(t *testing.T) {
+		f := setupFixture()
+		f.task.Attempts = 3
+
+		f.registry.Register(testTaskType, func(ctx context.Context, task *entity.Task) (any, error) {
+			panic("boom")
+		})
+		f.mockRepo.On("Update", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+		f.worker.handle(f.ctx, testWorkerID, f.task)
+
+		assert.Equal(t, entity.TaskStatusFailed, f.task.Status)
+		assert.Contains(t, f.task.Error, "boom")
+		f.mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("unknown task type fails fast", func(t *testing.T) {
+		f := setupFixture()
+
+		called := false
+		f.registry.Register(testTaskType, func(ctx context.Context, task *entity.Task) (any, error) {
+			called = true
+			return nil, nil
+		})
+		f.task.Type = "send-email"
+		f.mockRepo.On("Update", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+		f.worker.handle(f.ctx, testWorkerID, f.task)
+
+		assert.False(t, called)
+		assert.Equal(t, entity.TaskStatusFailed, f.task.Status)
+		assert.Contains(t, f.task.Error, ErrUnknownTaskType.Error())
+		assert.Contains(t, f.task.Error, "send-email")
+		f.mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("handler running past its timeout is retried", func(t *testing.T) {
+		f := setupFixture()
+		f.worker.timeout = 20 * time.Millisecond
+
+		f.registry.Register(testTaskType, func(ctx context.Context, task *entity.Task) (any, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		})
+		f.mockRepo.On("Update", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+		f.worker.handle(f.ctx, testWorkerID, f.task)
+
+		assert.Equal(t, entity.TaskStatusRetrying, f.task.Status)
+		assert.Contains(t, f.task.Error, ErrTimeout.Error())
+		assert.NotNil(t, f.task.NextRunAt)
+		f.mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("per task timeout overrides the default and times out the last attempt", func(t *testing.T) {
+		f := setupFixture()
+		f.worker.timeout = time.Hour
+		f.task.Timeout = entity.Duration(20 * time.Millisecond)
+		f.task.Attempts = 3
+
+		var deadline time.Time
+		f.registry.Register(testTaskType, func(ctx context.Context, task *entity.Task) (any, error) {
+			deadline, _ = ctx.Deadline()
+			<-ctx.Done()
+			return nil, nil
+		})
+		f.mockRepo.On("Update", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+		f.worker.handle(f.ctx, testWorkerID, f.task)
+
+		assert.WithinDuration(t, time.Now(), deadline, time.Second)
+		assert.Equal(t, entity.TaskStatusTimedOut, f.task.Status)
+		assert.True(t, f.task.IsDeadLettered())
+		assert.Contains(t, f.task.Error, "task timed out after 20ms")
+		f.mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("cancelled task stays cancelled", func(t *testing.T) {
+		f := setupFixture()
+
+		f.registry.Register(testTaskType, func(ctx context.Context, task *entity.Task) (any, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		})
+		f.mockRepo.On("Update", mock.Anything, mock.MatchedBy(func(updatedTask *entity.Task) bool {
+			return updatedTask.Status == entity.TaskStatusCancelled
+		}), mock.Anything).Return(nil)
+
+		ctx, cancel := context.WithCancelCause(f.ctx)
+		cancel(ErrCancelled)
+
+		f.worker.handle(ctx, testWorkerID, f.task)
+
+		assert.Equal(t, entity.TaskStatusCancelled, f.task.Status)
+		assert.Equal(t, 1, f.task.Attempts)
+		f.mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("completed task releases its dependents", func(t *testing.T) {
+		f := setupFixture()
+
+		f.mockRepo.On("Update", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+		f.mockRepo.ExpectedCalls = slices.DeleteFunc(f.mockRepo.ExpectedCalls, func(call *mock.Call) bool {
+			return call.Method == "ResolveDependents"
+		})
+		f.mockRepo.On("ResolveDependents", mock.Anything, mock.MatchedBy(func(task *entity.Task) bool {
+			return task.ID == f.task.ID && task.Status == entity.TaskStatusCompleted
+		})).Return([]*entity.Task{{ID: 2, Queue: entity.DefaultQueue, Status: entity.TaskStatusPending}}, nil).Once()
+
+		f.worker.handle(f.ctx, testWorkerID, f.task)
+
+		select {
+		case <-f.wakeup[entity.DefaultQueue]:
+		case <-time.After(time.Second):
+			t.Fatal("workers were not woken up for the released task")
+		}
+		f.mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("progress reported by the handler is saved", func(t *testing.T) {
+		f := setupFixture()
+
+		written := make(chan int, 10)
+		f.registry.Register(testTaskType, func(ctx context.Context, task *entity.Task) (any, error) {
+			Progress(ctx).Report(40, "resizing")
+
+			select {
+			case progress := <-written:
+				assert.Equal(t, 40, progress)
+			case <-time.After(time.Second):
+				t.Error("progress was not written while the task was running")
+			}
+
+			Progress(ctx).Report(140, "uploading")
+			return nil, errors.New("upload failed")
+		})
+		f.mockRepo.ExpectedCalls = slices.DeleteFunc(f.mockRepo.ExpectedCalls, func(call *mock.Call) bool {
+			return call.Method == "UpdateProgress"
+		})
+		f.mockRepo.On("UpdateProgress", mock.Anything, f.task.ID, 40, "resizing").
+			Run(func(args mock.Arguments) { written <- args.Int(2) }).
+			Return(nil).Once()
+		f.mockRepo.On("UpdateProgress", mock.Anything, f.task.ID, mock.Anything, mock.Anything).Return(nil).Maybe()
+		// The last report is saved with the outcome
+		f.mockRepo.On("Update", mock.Anything, mock.MatchedBy(func(task *entity.Task) bool {
+			return task.Status == entity.TaskStatusRetrying && task.Progress == 100 && task.ProgressMessage == "uploading"
+		}), mock.Anything).Return(nil).Once()
+
+		f.worker.handle(f.ctx, testWorkerID, f.task)
+
+		f.mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("completed task is fully done", func(t *testing.T) {
+		f := setupFixture()
+
+		f.registry.Register(testTaskType, func(ctx context.Context, task *entity.Task) (any, error) {
+			Progress(ctx).Report(60, "almost there")
+			return nil, nil
+		})
+		f.mockRepo.On("Update", mock.Anything, mock.MatchedBy(func(task *entity.Task) bool {
+			return task.Status == entity.TaskStatusCompleted && task.Progress == 100
+		}), mock.Anything).Return(nil).Once()
+
+		f.worker.handle(f.ctx, testWorkerID, f.task)
+
+		f.mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("failed task resolves its dependents", func(t *testing.T) {
+		f := setupFixture()
+
+		f.registry.Register(testTaskType, func(ctx context.Context, task *entity.Task) (any, error) {
+			return nil, Permanent(errors.New("invalid payload"))
+		})
+		f.mockRepo.On("Update", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+		f.mockRepo.ExpectedCalls = slices.DeleteFunc(f.mockRepo.ExpectedCalls, func(call *mock.Call) bool {
+			return call.Method == "ResolveDependents"
+		})
+		f.mockRepo.On("ResolveDependents", mock.Anything, mock.MatchedBy(func(task *entity.Task) bool {
+			return task.Status == entity.TaskStatusFailed
+		})).Return([]*entity.Task{{ID: 2, Queue: entity.DefaultQueue, Status: entity.TaskStatusCancelled}}, nil).Once()
+		subscription := f.bus.Subscribe(2)
+
+		f.worker.handle(f.ctx, testWorkerID, f.task)
+
+		require.Len(t, subscription.Events(), 1)
+		assert.Equal(t, entity.TaskStatusCancelled, (<-subscription.Events()).Status)
+		// Cancelled dependents have nothing to run
+		assert.Empty(t, f.wakeup[entity.DefaultQueue])
+		f.mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("task changes are published", func(t *testing.T) {
+		f := setupFixture()
+
+		subscription := f.bus.Subscribe(f.task.ID)
+		f.registry.Register(testTaskType, func(ctx context.Context, task *entity.Task) (any, error) {
+			Progress(ctx).Report(50, "halfway")
+			// Progress is written in the background, a write still pending
+			// when the handler returns is dropped.
+			assert.Eventually(t, func() bool {
+				return len(subscription.Events()) == 2
+			}, time.Second, time.Millisecond)
+			return map[string]int{"sent": 3}, nil
+		})
+		f.mockRepo.On("Update", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+		f.worker.handle(f.ctx, testWorkerID, f.task)
+
+		require.Len(t, subscription.Events(), 3)
+		running, progress, completed := <-subscription.Events(), <-subscription.Events(), <-subscription.Events()
+		assert.Equal(t, event.TypeStatus, running.Type)
+		assert.Equal(t, entity.TaskStatusRunning, running.Status)
+		assert.Equal(t, event.TypeProgress, progress.Type)
+		assert.Equal(t, 50, progress.Progress)
+		assert.Equal(t, "halfway", progress.ProgressMessage)
+		assert.Equal(t, event.TypeStatus, completed.Type)
+		assert.Equal(t, entity.TaskStatusCompleted, completed.Status)
+		assert.JSONEq(t, `{"sent":3}`, string(completed.Result))
+	})
+
+	t.Run("unsaved outcome is not published", func(t *testing.T) {
+		f := setupFixture()
+
+		f.mockRepo.On("Update", mock.Anything, mock.MatchedBy(func(task *entity.Task) bool {
+			return task.Status == entity.TaskStatusCompleted
+		}), mock.Anything).Return(errors.New("database connection failed"))
+		subscription := f.bus.Subscribe(f.task.ID)
+
+		f.worker.handle(f.ctx, testWorkerID, f.task)
+
+		require.Len(t, subscription.Events(), 1)
+		assert.Equal(t, entity.TaskStatusRunning, (<-subscription.Events()).Status)
+	})
+
+	t.Run("outcome of a task cancelled meanwhile is reported as cancelled", func(t *testing.T) {
+		f := setupFixture()
+
+		f.mockRepo.On("Update", mock.Anything, mock.MatchedBy(func(task *entity.Task) bool {
+			return task.Status == entity.TaskStatusCompleted
+		}), mock.Anything).Return(repository.ErrTaskCancelled).Once()
+		f.mockAttemptRepo.ExpectedCalls = nil
+		f.mockAttemptRepo.On("Create", mock.Anything, mock.Anything).Return(nil).Once()
+		f.mockAttemptRepo.On("Update", mock.Anything, mock.MatchedBy(func(attempt *entity.TaskAttempt) bool {
+			return attempt.Outcome == entity.TaskStatusCancelled
+		})).Return(nil).Once()
+		subscription := f.bus.Subscribe(f.task.ID)
+
+		f.worker.handle(f.ctx, testWorkerID, f.task)
+
+		assert.Equal(t, entity.TaskStatusCancelled, f.task.Status)
+		assert.Nil(t, f.task.Result)
+		require.Len(t, subscription.Events(), 1)
+		assert.Equal(t, entity.TaskStatusRunning, (<-subscription.Events()).Status)
+		f.mockRepo.AssertNotCalled(t, "ResolveDependents", mock.Anything, mock.Anything)
+		f.mockRepo.AssertExpectations(t)
+		f.mockAttemptRepo.AssertExpectations(t)
+	})
+
+	t.Run("outcome of a task recovered meanwhile is dropped", func(t *testing.T) {
+		f := setupFixture()
+
+		f.mockRepo.On("Update", mock.Anything, mock.MatchedBy(func(task *entity.Task) bool {
+			return task.Status == entity.TaskStatusCompleted
+		}), f.task.Attempts).Return(repository.ErrTaskLost).Once()
+		f.mockAttemptRepo.ExpectedCalls = nil
+		f.mockAttemptRepo.On("Create", mock.Anything, mock.Anything).Return(nil).Once()
+		subscription := f.bus.Subscribe(f.task.ID)
+
+		f.worker.handle(f.ctx, testWorkerID, f.task)
+
+		assert.Equal(t, entity.TaskStatusRunning, f.task.Status)
+		require.Len(t, subscription.Events(), 1)
+		assert.Equal(t, entity.TaskStatusRunning, (<-subscription.Events()).Status)
+		f.mockRepo.AssertNotCalled(t, "ResolveDependents", mock.Anything, mock.Anything)
+		f.mockRepo.AssertExpectations(t)
+		f.mockAttemptRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+
+	t.Run("attempt is recorded with its outcome", func(t *testing.T) {
+		f := setupFixture()
+
+		f.registry.Register(testTaskType, func(ctx context.Context, task *entity.Task) (any, error) {
+			return nil, errors.New("smtp unavailable")
+		})
+		f.mockRepo.On("Update", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+		var created entity.TaskAttempt
+		f.mockAttemptRepo.ExpectedCalls = nil
+		f.mockAttemptRepo.On("Create", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			created = *args.Get(1).(*entity.TaskAttempt)
+		}).Return(nil).Once()
+		f.mockAttemptRepo.On("Update", mock.Anything, mock.Anything).Return(nil).Once()
+
+		f.worker.handle(f.ctx, testWorkerID, f.task)
+
+		assert.Equal(t, f.task.ID, created.TaskID)
+		assert.Equal(t, 1, created.Attempt)
+		assert.Equal(t, testWorkerID, created.WorkerID)
+		assert.Equal(t, entity.TaskStatusRunning, created.Outcome)
+		assert.Nil(t, created.FinishedAt)
+
+		finished := f.mockAttemptRepo.Calls[1].Arguments.Get(1).(*entity.TaskAttempt)
+		require.NotNil(t, finished.FinishedAt)
+		assert.Equal(t, entity.TaskStatusRetrying, finished.Outcome)
+		assert.Equal(t, "smtp unavailable", finished.Error)
+		assert.Equal(t, finished.FinishedAt.Sub(finished.StartedAt), finished.Duration)
+		f.mockAttemptRepo.AssertExpectations(t)
+	})
+
+	t.Run("attempt is not recorded for a task that does not start", func(t *testing.T) {
+		f := setupFixture()
+		f.task.Status = entity.TaskStatusCompleted
+
+		f.worker.handle(f.ctx, testWorkerID, f.task)
+
+		f.mockAttemptRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+}
+
+func TestRetryPolicy(t *testing.T) {
+	t.Run("backoff grows exponentially up to max delay", func(t *testing.T) {
+		policy := RetryPolicy{BaseDelay: time.Second, MaxDelay: 10 * time.Second}
+
+		assert.Equal(t, time.Second, policy.Backoff(1))
+		assert.Equal(t, 2*time.Second, policy.Backoff(2))
+		assert.Equal(t, 4*time.Second, policy.Backoff(3))
+		assert.Equal(t, 10*time.Second, policy.Backoff(5))
+		assert.Equal(t, 10*time.Second, policy.Backoff(1000))
+	})
+
+	t.Run("backoff without max delay does not overflow", func(t *testing.T) {
+		policy := RetryPolicy{BaseDelay: time.Second, Jitter: 0.2}
+
+		assert.Equal(t, time.Duration(math.MaxInt64), policy.Backoff(64))
+		assert.Equal(t, time.Duration(math.MaxInt64), policy.Backoff(10000))
+	})
+
+	t.Run("jitter stays within bounds", func(t *testing.T) {
+		policy := RetryPolicy{BaseDelay: time.Second, MaxDelay: time.Minute, Jitter: 0.5}
+
+		for i := 0; i < 100; i++ {
+			delay := policy.Backoff(2)
+			assert.GreaterOrEqual(t, delay, 2*time.Second)
+			assert.LessOrEqual(t, delay, 3*time.Second)
+		}
+	})
+
+	t.Run("should retry until max attempts", func(t *testing.T) {
+		policy := RetryPolicy{MaxAttempts: 3}
+		err := errors.New("boom")
+
+		assert.True(t, policy.ShouldRetry(err, 1, 0))
+		assert.True(t, policy.ShouldRetry(err, 2, 0))
+		assert.False(t, policy.ShouldRetry(err, 3, 0))
+		assert.True(t, policy.ShouldRetry(err, 3, 5))
+		assert.False(t, policy.ShouldRetry(Permanent(err), 1, 0))
+	})
+}
+
+func TestRegistry(t *testing.T) {
+	t.Run("lookup returns registered handler", func(t *testing.T) {
+		registry := NewRegistry()
+		registry.Register("send-email", func(ctx context.Context, task *entity.Task) (any, error) {
+			return nil, errors.New("sent")
+		})
+
+		handler, err := registry.Lookup("send-email")
+		assert.NoError(t, err)
+		_, err = handler(context.Background(), &entity.Task{})
+		assert.EqualError(t, err, "sent")
+	})
+
+	t.Run("lookup of unknown type returns error", func(t *testing.T) {
+		registry := NewRegistry()
+
+		_, err := registry.Lookup("send-email")
+		assert.ErrorIs(t, err, ErrUnknownTaskType)
+	})
+
+	t.Run("register panics on empty type", func(t *testing.T) {
+		registry := NewRegistry()
+
+		assert.Panics(t, func() {
+			registry.Register("", func(ctx context.Context, task *entity.Task) (any, error) { return nil, nil })
+		})
+	})
 }
 
 func TestTaskWorker_Run(t *testing.T) {
 	t.Run("worker starts with correct number of goroutines", func(t *testing.T) {
 		f := setupFixture()
+		f.worker.queues[entity.DefaultQueue] = 3
 
-		f.worker.Run(f.ctx)
+		started := make(chan struct{}, 3)
+		release := make(chan struct{})
+		f.registry.Register(testTaskType, func(ctx context.Context, task *entity.Task) (any, error) {
+			started <- struct{}{}
+			<-release
+			return nil, nil
+		})
 
-		// Give workers time to start
-		time.Sleep(100 * time.Millisecond)
+		for i := uint64(1); i <= 3; i++ {
+			f.mockRepo.On("ClaimNext", mock.Anything, entity.DefaultQueue, mock.Anything).Return(&entity.Task{ID: i, Type: testTaskType, Status: entity.TaskStatusQueued}, nil).Once()
+		}
+		f.mockRepo.On("ClaimNext", mock.Anything, entity.DefaultQueue, mock.Anything).Return(nil, repository.ErrNoTaskAvailable)
+		f.mockRepo.On("Update", mock.Anything, mock.Anything, mock.Anything).Return(nil).Times(3)
+
+		ctx, cancel := context.WithCancel(f.ctx)
+		f.worker.Run(ctx)
+
+		// All three tasks must be in flight at the same time
+		for i := 0; i < 3; i++ {
+			select {
+			case <-started:
+			case <-time.After(2 * time.Second):
+				t.Fatalf("only %d workers picked up a task", i)
+			}
+		}
+		close(release)
+
+		cancel()
+		f.worker.wg.Wait()
+		f.mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("slow queue does not block other queues", func(t *testing.T) {
+		f := setupFixture()
+		f.worker.queues = map[string]int{"reports": 1, "emails": 1}
+		f.worker.wakeup = Wakeup{"reports": make(chan struct{}, 1), "emails": make(chan struct{}, 1)}
+
+		release := make(chan struct{})
+		f.registry.Register("report", func(ctx context.Context, task *entity.Task) (any, error) {
+			<-release
+			return nil, nil
+		})
 
-		// Verify workers are running by checking if they can process tasks
-		f.mockRepo.On("Update", mock.Anything, mock.Anything).Return(nil)
+		report := &entity.Task{ID: 1, Type: "report", Queue: "reports", Status: entity.TaskStatusQueued}
+		email := &entity.Task{ID: 2, Type: testTaskType, Queue: "emails", Status: entity.TaskStatusQueued}
+		f.mockRepo.On("ClaimNext", mock.Anything, "reports", mock.Anything).Return(report, nil).Once()
+		f.mockRepo.On("ClaimNext", mock.Anything, "reports", mock.Anything).Return(nil, repository.ErrNoTaskAvailable)
+		f.mockRepo.On("ClaimNext", mock.Anything, "emails", mock.Anything).Return(email, nil).Once()
+		f.mockRepo.On("ClaimNext", mock.Anything, "emails", mock.Anything).Return(nil, repository.ErrNoTaskAvailable)
+
+		// The tasks are written by the workers, their saved status is
+		// recorded instead of reading them concurrently.
+		var mu sync.Mutex
+		saved := make(map[uint64]entity.TaskStatus)
+		status := func(id uint64) entity.TaskStatus {
+			mu.Lock()
+			defer mu.Unlock()
+			return saved[id]
+		}
+		f.mockRepo.On("Update", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			task := args.Get(1).(*entity.Task)
+			mu.Lock()
+			saved[task.ID] = task.Status
+			mu.Unlock()
+		}).Return(nil)
 
-		f.taskChannel <- f.task
+		ctx, cancel := context.WithCancel(f.ctx)
+		f.worker.Run(ctx)
+
+		assert.Eventually(t, func() bool {
+			return status(email.ID) == entity.TaskStatusCompleted
+		}, time.Second, 10*time.Millisecond)
+		assert.NotEqual(t, entity.TaskStatusCompleted, status(report.ID))
+
+		close(release)
+		cancel()
+		f.worker.wg.Wait()
+
+		assert.Equal(t, entity.TaskStatusCompleted, status(report.ID))
+	})
+}
+
+func TestTaskWorker_Shutdown(t *testing.T) {
+	t.Run("waits for in-flight task to finish", func(t *testing.T) {
+		f := setupFixture()
 
-		// Wait for task to be processed (handle sleeps 1-5 seconds)
-		time.Sleep(6 * time.Second)
+		started := make(chan struct{})
+		f.registry.Register(testTaskType, func(ctx context.Context, task *entity.Task) (any, error) {
+			close(started)
+			time.Sleep(100 * time.Millisecond)
+			return nil, nil
+		})
+		f.mockRepo.On("ClaimNext", mock.Anything, entity.DefaultQueue, mock.Anything).Return(f.task, nil).Once()
+		f.mockRepo.On("ClaimNext", mock.Anything, entity.DefaultQueue, mock.Anything).Return(nil, repository.ErrNoTaskAvailable)
+		f.mockRepo.On("Update", mock.Anything, mock.Anything, mock.Anything).Return(nil)
 
+		f.worker.Run(f.ctx)
+		<-started
+
+		ctx, cancel := context.WithTimeout(f.ctx, 2*time.Second)
+		defer cancel()
+
+		err := f.worker.Shutdown(ctx)
+		require.NoError(t, err)
 		assert.Equal(t, entity.TaskStatusCompleted, f.task.Status)
 		f.mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("returns unfinished task to pending after deadline", func(t *testing.T) {
+		f := setupFixture()
+
+		started := make(chan struct{})
+		f.registry.Register(testTaskType, func(ctx context.Context, task *entity.Task) (any, error) {
+			close(started)
+			<-ctx.Done()
+			return nil, ctx.Err()
+		})
+		f.mockRepo.On("ClaimNext", mock.Anything, entity.DefaultQueue, mock.Anything).Return(f.task, nil).Once()
+		f.mockRepo.On("ClaimNext", mock.Anything, entity.DefaultQueue, mock.Anything).Return(nil, repository.ErrNoTaskAvailable)
+		f.mockRepo.On("Update", mock.Anything, mock.MatchedBy(func(updatedTask *entity.Task) bool {
+			return updatedTask.Status == entity.TaskStatusPending
+		}), mock.Anything).Return(nil)
+
+		f.worker.Run(f.ctx)
+		<-started
+
+		ctx, cancel := context.WithTimeout(f.ctx, 50*time.Millisecond)
+		defer cancel()
+
+		err := f.worker.Shutdown(ctx)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Equal(t, entity.TaskStatusPending, f.task.Status)
+		assert.Equal(t, 0, f.task.Attempts)
+		assert.False(t, f.task.IsDeadLettered())
+		f.mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("releases tasks of handlers ignoring the abort", func(t *testing.T) {
+		f := setupFixture()
+		f.worker.abortGrace = 20 * time.Millisecond
+
+		started := make(chan struct{})
+		unblock := make(chan struct{})
+		defer close(unblock)
+		f.registry.Register(testTaskType, func(ctx context.Context, task *entity.Task) (any, error) {
+			close(started)
+			<-unblock
+			return nil, nil
+		})
+		f.mockRepo.On("ClaimNext", mock.Anything, entity.DefaultQueue, mock.Anything).Return(f.task, nil).Once()
+		f.mockRepo.On("ClaimNext", mock.Anything, entity.DefaultQueue, mock.Anything).Return(nil, repository.ErrNoTaskAvailable)
+		f.mockRepo.On("Update", mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+		released := &entity.Task{ID: f.task.ID, Queue: entity.DefaultQueue, Status: entity.TaskStatusPending}
+		f.mockRepo.On("Release", mock.Anything, []uint64{f.task.ID}).Return([]*entity.Task{released}, nil).Once()
+
+		f.worker.Run(f.ctx)
+		<-started
+
+		ctx, cancel := context.WithTimeout(f.ctx, 50*time.Millisecond)
+		defer cancel()
+
+		err := f.worker.Shutdown(ctx)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		f.mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("idle workers stop immediately", func(t *testing.T) {
+		f := setupFixture()
+		f.worker.queues[entity.DefaultQueue] = 3
+
+		f.mockRepo.On("ClaimNext", mock.Anything, entity.DefaultQueue, mock.Anything).Return(nil, repository.ErrNoTaskAvailable)
+
+		f.worker.Run(f.ctx)
+		time.Sleep(20 * time.Millisecond)
+
+		ctx, cancel := context.WithTimeout(f.ctx, time.Second)
+		defer cancel()
+
+		require.NoError(t, f.worker.Shutdown(ctx))
+	})
+}
+
+func TestTaskWorker_schedule(t *testing.T) {
+	t.Run("wakes workers when a delayed task becomes due", func(t *testing.T) {
+		f := setupFixture()
+		f.worker.pollInterval = time.Hour
+
+		f.mockRepo.ExpectedCalls = nil
+		nextRunAt := time.Now().Add(50 * time.Millisecond)
+		f.mockRepo.On("FindNextRunAt", mock.Anything).Return(&nextRunAt, nil).Once()
+		f.mockRepo.On("FindNextRunAt", mock.Anything).Return(nil, nil)
+
+		ctx, cancel := context.WithCancel(f.ctx)
+		f.worker.wg.Add(1)
+		go f.worker.schedule(ctx)
+
+		select {
+		case <-f.wakeup[entity.DefaultQueue]:
+			t.Fatal("workers woken up before the task was due")
+		case <-time.After(20 * time.Millisecond):
+		}
+
+		select {
+		case <-f.wakeup[entity.DefaultQueue]:
+			assert.False(t, time.Now().Before(nextRunAt))
+		case <-time.After(time.Second):
+			t.Fatal("workers were not woken up when the task became due")
+		}
+
+		cancel()
+		f.worker.wg.Wait()
+		f.mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("does not wake workers for tasks due after the poll interval", func(t *testing.T) {
+		f := setupFixture()
+		f.worker.pollInterval = 20 * time.Millisecond
 
-		// Cleanup
-		cancelCtx, cancel := context.WithCancel(f.ctx)
+		f.mockRepo.ExpectedCalls = nil
+		nextRunAt := time.Now().Add(time.Hour)
+		f.mockRepo.On("FindNextRunAt", mock.Anything).Return(&nextRunAt, nil)
+
+		ctx, cancel := context.WithCancel(f.ctx)
+		f.worker.wg.Add(1)
+		go f.worker.schedule(ctx)
+
+		time.Sleep(100 * time.Millisecond)
 		cancel()
-		f.worker.wroker(cancelCtx)
 		f.worker.wg.Wait()
+
+		assert.Empty(t, f.wakeup[entity.DefaultQueue])
 	})
 }
 
-func TestTaskWorker_wroker(t *testing.T) {
-	t.Run("worker processes tasks from channel", func(t *testing.T) {
+func TestTaskWorker_watchCancellations(t *testing.T) {
+	t.Run("aborts the handler of a cancelled task", func(t *testing.T) {
 		f := setupFixture()
 
-		f.mockRepo.On("Update", mock.Anything, mock.Anything).Return(nil)
+		started := make(chan struct{})
+		f.registry.Register(testTaskType, func(ctx context.Context, task *entity.Task) (any, error) {
+			close(started)
+			<-ctx.Done()
+			return nil, ctx.Err()
+		})
 
-		// Start worker in goroutine
-		f.worker.wg.Add(1)
-		go f.worker.wroker(f.ctx)
+		f.mockRepo.ExpectedCalls = nil
+		f.mockRepo.On("FindNextRunAt", mock.Anything).Return(nil, nil).Maybe()
+		f.mockRepo.On("FindCancelled", mock.Anything, []uint64{f.task.ID}).Return([]uint64{f.task.ID}, nil)
+		f.mockRepo.On("ClaimNext", mock.Anything, entity.DefaultQueue, mock.Anything).Return(f.task, nil).Once()
+		f.mockRepo.On("ClaimNext", mock.Anything, entity.DefaultQueue, mock.Anything).Return(nil, repository.ErrNoTaskAvailable)
+		f.mockRepo.On("Update", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+		f.mockRepo.On("ResolveDependents", mock.Anything, mock.Anything).Return(nil, nil)
+
+		ctx, cancel := context.WithCancel(f.ctx)
+		f.worker.Run(ctx)
+		<-started
 
-		// Send task to channel
-		f.taskChannel <- f.task
+		assert.Eventually(t, func() bool {
+			f.worker.mu.Lock()
+			defer f.worker.mu.Unlock()
+			return len(f.worker.inflight) == 0
+		}, time.Second, 10*time.Millisecond)
 
-		// Wait for task to be processed (handle sleeps 1-5 seconds)
-		time.Sleep(6 * time.Second)
+		cancel()
+		f.worker.wg.Wait()
+
+		assert.Equal(t, entity.TaskStatusCancelled, f.task.Status)
+		f.mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestTaskWorker_heartbeat(t *testing.T) {
+	t.Run("keeps the heartbeat of a running task fresh", func(t *testing.T) {
+		f := setupFixture()
+		f.worker.heartbeatTimeout = 30 * time.Millisecond
+
+		beat := make(chan struct{}, 10)
+		f.registry.Register(testTaskType, func(ctx context.Context, task *entity.Task) (any, error) {
+			<-beat
+			return nil, nil
+		})
+		f.mockRepo.On("Heartbeat", mock.Anything, f.task.ID).Run(func(mock.Arguments) {
+			beat <- struct{}{}
+		}).Return(nil)
+		f.mockRepo.On("Update", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+		f.worker.process(f.task, testWorkerID)
 
 		assert.Equal(t, entity.TaskStatusCompleted, f.task.Status)
 		f.mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("aborts the handler of a task no longer held", func(t *testing.T) {
+		f := setupFixture()
+		f.worker.heartbeatTimeout = 30 * time.Millisecond
+
+		var cause error
+		f.registry.Register(testTaskType, func(ctx context.Context, task *entity.Task) (any, error) {
+			<-ctx.Done()
+			cause = context.Cause(ctx)
+			return nil, ctx.Err()
+		})
+		f.mockRepo.On("Heartbeat", mock.Anything, f.task.ID).Return(repository.ErrTaskLost).Once()
+		f.mockRepo.On("Update", mock.Anything, mock.Anything, mock.Anything).Return(repository.ErrTaskLost).Once()
+
+		f.worker.process(f.task, testWorkerID)
+
+		require.ErrorIs(t, cause, repository.ErrTaskLost)
+		f.mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("is not sent when disabled", func(t *testing.T) {
+		f := setupFixture()
+
+		f.registry.Register(testTaskType, func(ctx context.Context, task *entity.Task) (any, error) {
+			time.Sleep(30 * time.Millisecond)
+			return nil, nil
+		})
+		f.mockRepo.On("Update", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+		f.worker.process(f.task, testWorkerID)
+
+		f.mockRepo.AssertNotCalled(t, "Heartbeat", mock.Anything, mock.Anything)
+	})
+}
+
+func TestTaskWorker_recover(t *testing.T) {
+	t.Run("retries and fails the tasks of lost workers", func(t *testing.T) {
+		f := setupFixture()
+		f.worker.heartbeatTimeout = 30 * time.Second
+
+		retrying := &entity.Task{ID: 1, Queue: entity.DefaultQueue, Status: entity.TaskStatusRetrying, Attempts: 1}
+		failed := &entity.Task{ID: 2, Queue: entity.DefaultQueue, Status: entity.TaskStatusFailed, Attempts: 3}
+
+		subscription := f.bus.Subscribe(0)
+		defer subscription.Close()
+
+		f.mockRepo.ExpectedCalls = nil
+		f.mockRepo.On("RecoverAbandoned", mock.Anything, 30*time.Second, 3).Return([]*entity.Task{retrying, failed}, nil).Once()
+		f.mockRepo.On("ResolveDependents", mock.Anything, failed).Return(nil, nil).Once()
+		f.mockRepo.On("ResolveBlocked", mock.Anything).Return(nil, nil).Once()
+
+		f.worker.recover(f.ctx)
+
+		assert.Len(t, f.wakeup[entity.DefaultQueue], 1)
+		require.Len(t, subscription.Events(), 2)
+		assert.Equal(t, entity.TaskStatusRetrying, (<-subscription.Events()).Status)
+		assert.Equal(t, entity.TaskStatusFailed, (<-subscription.Events()).Status)
+		f.mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("nothing happens when recovery fails", func(t *testing.T) {
+		f := setupFixture()
+
+		f.mockRepo.ExpectedCalls = nil
+		f.mockRepo.On("RecoverAbandoned", mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("database connection failed")).Once()
+		f.mockRepo.On("ResolveBlocked", mock.Anything).Return(nil, errors.New("database connection failed")).Once()
+
+		f.worker.recover(f.ctx)
+
+		assert.Empty(t, f.wakeup[entity.DefaultQueue])
+		f.mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("resolves blocked tasks whose dependencies already finished", func(t *testing.T) {
+		f := setupFixture()
+
+		released := &entity.Task{ID: 3, Queue: entity.DefaultQueue, Status: entity.TaskStatusPending}
+		cancelled := &entity.Task{ID: 4, Queue: entity.DefaultQueue, Status: entity.TaskStatusCancelled}
+
+		subscription := f.bus.Subscribe(0)
+		defer subscription.Close()
+
+		f.mockRepo.ExpectedCalls = nil
+		f.mockRepo.On("RecoverAbandoned", mock.Anything, mock.Anything, mock.Anything).Return(nil, nil).Once()
+		f.mockRepo.On("ResolveBlocked", mock.Anything).Return([]*entity.Task{released, cancelled}, nil).Once()
+
+		f.worker.recover(f.ctx)
+
+		assert.Len(t, f.wakeup[entity.DefaultQueue], 1)
+		require.Len(t, subscription.Events(), 2)
+		assert.Equal(t, entity.TaskStatusPending, (<-subscription.Events()).Status)
+		assert.Equal(t, entity.TaskStatusCancelled, (<-subscription.Events()).Status)
+		f.mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestTaskWorker_wroker(t *testing.T) {
+	t.Run("worker processes claimed tasks", func(t *testing.T) {
+		f := setupFixture()
+
+		f.mockRepo.On("ClaimNext", mock.Anything, entity.DefaultQueue, mock.Anything).Return(f.task, nil).Once()
+		f.mockRepo.On("ClaimNext", mock.Anything, entity.DefaultQueue, mock.Anything).Return(nil, repository.ErrNoTaskAvailable)
+		f.mockRepo.On("Update", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+		ctx, cancel := context.WithCancel(f.ctx)
+		f.worker.wg.Add(1)
+		go f.worker.wroker(ctx, entity.DefaultQueue, testWorkerID)
+
+		// Wait for task to be processed
+		time.Sleep(100 * time.Millisecond)
 
-		// Cleanup: cancel context to stop worker
-		cancelCtx, cancel := context.WithCancel(f.ctx)
 		cancel()
-		f.worker.wroker(cancelCtx)
 		f.worker.wg.Wait()
+
+		assert.Equal(t, entity.TaskStatusCompleted, f.task.Status)
+		f.mockRepo.AssertExpectations(t)
 	})
 
 	t.Run("worker stops on context cancellation", func(t *testing.T) {
 		f := setupFixture()
 
+		f.mockRepo.On("ClaimNext", mock.Anything, entity.DefaultQueue, mock.Anything).Return(nil, repository.ErrNoTaskAvailable)
+
 		ctx, cancel := context.WithCancel(context.Background())
 
 		// Start worker
 		f.worker.wg.Add(1)
-		go f.worker.wroker(ctx)
+		go f.worker.wroker(ctx, entity.DefaultQueue, testWorkerID)
 
 		// Give worker time to start
 		time.Sleep(100 * time.Millisecond)
@@ -168,38 +1144,96 @@ func TestTaskWorker_wroker(t *testing.T) {
 			ID:          1,
 			Title:       "Task 1",
 			Description: "Description 1",
-			Status:      entity.TaskStatusPending,
+			Type:        testTaskType,
+			Status:      entity.TaskStatusQueued,
 		}
 
 		task2 := &entity.Task{
 			ID:          2,
 			Title:       "Task 2",
 			Description: "Description 2",
-			Status:      entity.TaskStatusPending,
+			Type:        testTaskType,
+			Status:      entity.TaskStatusQueued,
 		}
 
-		f.mockRepo.On("Update", mock.Anything, mock.Anything).Return(nil).Twice()
+		f.mockRepo.On("ClaimNext", mock.Anything, entity.DefaultQueue, mock.Anything).Return(task1, nil).Once()
+		f.mockRepo.On("ClaimNext", mock.Anything, entity.DefaultQueue, mock.Anything).Return(task2, nil).Once()
+		f.mockRepo.On("ClaimNext", mock.Anything, entity.DefaultQueue, mock.Anything).Return(nil, repository.ErrNoTaskAvailable)
+		f.mockRepo.On("Update", mock.Anything, mock.Anything, mock.Anything).Return(nil).Twice()
 
-		// Start worker
+		ctx, cancel := context.WithCancel(f.ctx)
 		f.worker.wg.Add(1)
-		go f.worker.wroker(f.ctx)
+		go f.worker.wroker(ctx, entity.DefaultQueue, testWorkerID)
 
-		// Send tasks to channel
-		f.taskChannel <- task1
-		f.taskChannel <- task2
+		// Wait for tasks to be processed
+		time.Sleep(200 * time.Millisecond)
 
-		// Wait for tasks to be processed (handle sleeps 1-5 seconds per task)
-		time.Sleep(12 * time.Second)
+		cancel()
+		f.worker.wg.Wait()
 
 		assert.Equal(t, entity.TaskStatusCompleted, task1.Status)
 		assert.Equal(t, entity.TaskStatusCompleted, task2.Status)
 		f.mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("worker wakes up before poll interval", func(t *testing.T) {
+		f := setupFixture()
+		f.worker.pollInterval = time.Hour
+
+		f.mockRepo.On("ClaimNext", mock.Anything, entity.DefaultQueue, mock.Anything).Return(nil, repository.ErrNoTaskAvailable).Once()
+		f.mockRepo.On("ClaimNext", mock.Anything, entity.DefaultQueue, mock.Anything).Return(f.task, nil).Once()
+		f.mockRepo.On("ClaimNext", mock.Anything, entity.DefaultQueue, mock.Anything).Return(nil, repository.ErrNoTaskAvailable)
+		f.mockRepo.On("Update", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+		ctx, cancel := context.WithCancel(f.ctx)
+		f.worker.wg.Add(1)
+		go f.worker.wroker(ctx, entity.DefaultQueue, testWorkerID)
+
+		time.Sleep(50 * time.Millisecond)
+		f.wakeup[entity.DefaultQueue] <- struct{}{}
+		time.Sleep(100 * time.Millisecond)
 
-		// Cleanup
-		cancelCtx, cancel := context.WithCancel(f.ctx)
 		cancel()
-		f.worker.wroker(cancelCtx)
 		f.worker.wg.Wait()
+
+		assert.Equal(t, entity.TaskStatusCompleted, f.task.Status)
+		f.mockRepo.AssertExpectations(t)
 	})
-}
 
+	t.Run("worker keeps polling after claim error", func(t *testing.T) {
+		f := setupFixture()
+
+		f.mockRepo.On("ClaimNext", mock.Anything, entity.DefaultQueue, mock.Anything).Return(nil, errors.New("database connection failed")).Once()
+		f.mockRepo.On("ClaimNext", mock.Anything, entity.DefaultQueue, mock.Anything).Return(f.task, nil).Once()
+		f.mockRepo.On("ClaimNext", mock.Anything, entity.DefaultQueue, mock.Anything).Return(nil, repository.ErrNoTaskAvailable)
+		f.mockRepo.On("Update", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+		ctx, cancel := context.WithCancel(f.ctx)
+		f.worker.wg.Add(1)
+		go f.worker.wroker(ctx, entity.DefaultQueue, testWorkerID)
+
+		time.Sleep(100 * time.Millisecond)
+
+		cancel()
+		f.worker.wg.Wait()
+
+		assert.Equal(t, entity.TaskStatusCompleted, f.task.Status)
+		f.mockRepo.AssertExpectations(t)
+	})
+	t.Run("worker claims with the configured priority aging", func(t *testing.T) {
+		f := setupFixture()
+
+		f.mockRepo.On("ClaimNext", mock.Anything, entity.DefaultQueue, time.Minute).Return(nil, repository.ErrNoTaskAvailable)
+
+		ctx, cancel := context.WithCancel(f.ctx)
+		f.worker.wg.Add(1)
+		go f.worker.wroker(ctx, entity.DefaultQueue, testWorkerID)
+
+		time.Sleep(50 * time.Millisecond)
+
+		cancel()
+		f.worker.wg.Wait()
+
+		f.mockRepo.AssertExpectations(t)
+	})
+}
@@ -6,6 +6,6 @@ import (
 
 type Worker[T any] interface {
 	Run(ctx context.Context)
-	Shutdown()
-	handle(ctx context.Context, command T)
+	Shutdown(ctx context.Context) error
+	handle(ctx context.Context, workerID string, command T)
 }
@@ -2,6 +2,7 @@ package apperror
 
 import (
 	"fmt"
+	"time"
 )
 
 type AppError struct {
@@ -9,6 +10,20 @@ type AppError struct {
 	Status  int    `json:"status"`
 	Message string `json:"message"`
 	Details string `json:"details"`
+	// RetryAfter is sent as the Retry-After header when greater than zero.
+	RetryAfter time.Duration `json:"-"`
+	// Fields lists the offending request fields of a validation error.
+	Fields []FieldError `json:"fields,omitempty"`
+}
+
+// FieldError describes a request field that failed a validation rule, Param
+// is the argument of the rule such as the 3 of min=3. Message explains
+// failures that are not a rule of the validate tag.
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule,omitempty"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message,omitempty"`
 }
 
 func NewAppError(code string, status int, message, details string) *AppError {
@@ -20,5 +35,18 @@ func (e *AppError) Error() string {
 }
 
 func (e *AppError) Wrap(err error) *AppError {
-	return &AppError{Code: e.Code, Status: e.Status, Message: e.Message, Details: fmt.Sprintf("%s: %s", e.Details, err.Error())}
+	details := err.Error()
+	if e.Details != "" {
+		details = fmt.Sprintf("%s: %s", e.Details, details)
+	}
+
+	return &AppError{Code: e.Code, Status: e.Status, Message: e.Message, Details: details, RetryAfter: e.RetryAfter, Fields: e.Fields}
+}
+
+func (e *AppError) WithRetryAfter(retryAfter time.Duration) *AppError {
+	return &AppError{Code: e.Code, Status: e.Status, Message: e.Message, Details: e.Details, RetryAfter: retryAfter, Fields: e.Fields}
+}
+
+func (e *AppError) WithFields(fields ...FieldError) *AppError {
+	return &AppError{Code: e.Code, Status: e.Status, Message: e.Message, Details: e.Details, RetryAfter: e.RetryAfter, Fields: fields}
 }
@@ -26,3 +26,21 @@ func InternalServerError(message string) *AppError {
 		Details: "",
 	}
 }
+
+func TooManyRequests(message string) *AppError {
+	return &AppError{
+		Code:    "TOO_MANY_REQUESTS",
+		Status:  429,
+		Message: message,
+		Details: "",
+	}
+}
+
+func ServiceUnavailable(message string) *AppError {
+	return &AppError{
+		Code:    "SERVICE_UNAVAILABLE",
+		Status:  503,
+		Message: message,
+		Details: "",
+	}
+}
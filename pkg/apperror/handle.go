@@ -1,6 +1,9 @@
 package apperror
 
 import (
+	"math"
+	"strconv"
+
 	"github.com/gofiber/fiber/v3"
 )
 
@@ -19,9 +22,19 @@ func HandleError(c fiber.Ctx, err error) error {
 		}
 	}
 
-	return c.Status(appErr.Status).JSON(fiber.Map{
+	if appErr.RetryAfter > 0 {
+		seconds := int(math.Ceil(appErr.RetryAfter.Seconds()))
+		c.Set(fiber.HeaderRetryAfter, strconv.Itoa(seconds))
+	}
+
+	body := fiber.Map{
 		"code":    appErr.Code,
 		"message": appErr.Message,
 		"details": appErr.Details,
-	})
+	}
+	if len(appErr.Fields) > 0 {
+		body["fields"] = appErr.Fields
+	}
+
+	return c.Status(appErr.Status).JSON(body)
 }
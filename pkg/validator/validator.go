@@ -0,0 +1,81 @@
+package validator
+
+import (
+	"errors"
+	"reflect"
+	"slices"
+	"strings"
+	"task-pool/pkg/apperror"
+
+	govalidator "github.com/go-playground/validator/v10"
+)
+
+// Validator enforces the validate struct tags of request bodies and queries.
+// It implements fiber.StructValidator, so every c.Bind() call is validated.
+type Validator struct {
+	validate *govalidator.Validate
+}
+
+func New() *Validator {
+	validate := govalidator.New(govalidator.WithRequiredStructEnabled())
+	validate.RegisterTagNameFunc(fieldName)
+
+	return &Validator{validate: validate}
+}
+
+// Validate returns an apperror.BadRequest listing every field that breaks
+// one of its rules.
+func (v *Validator) Validate(out any) error {
+	err := v.validate.Struct(out)
+	if err == nil {
+		return nil
+	}
+
+	var validationErrs govalidator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return apperror.BadRequest("invalid request").Wrap(err)
+	}
+
+	fields := make([]apperror.FieldError, 0, len(validationErrs))
+	for _, fieldErr := range validationErrs {
+		fields = append(fields, apperror.FieldError{
+			Field: fieldPath(fieldErr.Namespace()),
+			Rule:  fieldErr.Tag(),
+			Param: fieldErr.Param(),
+		})
+	}
+
+	return apperror.BadRequest("validation failed").WithFields(fields...)
+}
+
+// embedded names embedded structs, whose fields JSON flattens into the parent.
+const embedded = "<embedded>"
+
+// fieldPath turns a validator namespace such as CreateTasks.tasks[3].title
+// into the path of the field in the request, tasks[3].title.
+func fieldPath(namespace string) string {
+	segments := strings.Split(namespace, ".")[1:]
+
+	return strings.Join(slices.DeleteFunc(segments, func(segment string) bool {
+		return segment == embedded
+	}), ".")
+}
+
+// fieldName reports fields by the name clients send them with.
+func fieldName(field reflect.StructField) string {
+	if field.Anonymous && field.Tag.Get("json") == "" {
+		return embedded
+	}
+
+	for _, tag := range []string{"json", "query"} {
+		name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+		if name == "-" {
+			return ""
+		}
+		if name != "" {
+			return name
+		}
+	}
+
+	return field.Name
+}
@@ -0,0 +1,69 @@
+package validator
+
+import (
+	"task-pool/internal/service/contracts"
+	"task-pool/pkg/apperror"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidator_Validate(t *testing.T) {
+	v := New()
+
+	t.Run("valid request", func(t *testing.T) {
+		err := v.Validate(&contracts.CreateTask{
+			Title:       "Send email",
+			Description: "Welcome email",
+			Type:        "send-email",
+			Priority:    5,
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("every offending field is reported", func(t *testing.T) {
+		err := v.Validate(&contracts.CreateTask{
+			Title:       "",
+			Description: "ab",
+			Type:        "send-email",
+			Priority:    11,
+		})
+
+		var appErr *apperror.AppError
+		require.ErrorAs(t, err, &appErr)
+		assert.Equal(t, "BAD_REQUEST", appErr.Code)
+		assert.Equal(t, []apperror.FieldError{
+			{Field: "title", Rule: "required"},
+			{Field: "description", Rule: "min", Param: "3"},
+			{Field: "priority", Rule: "max", Param: "10"},
+		}, appErr.Fields)
+	})
+
+	t.Run("nested fields are reported by index", func(t *testing.T) {
+		valid := contracts.CreateTask{Title: "Send email", Description: "Welcome email", Type: "send-email"}
+		invalid := valid
+		invalid.Type = ""
+
+		err := v.Validate(&contracts.CreateTasks{Tasks: []contracts.CreateTask{valid, invalid}})
+
+		var appErr *apperror.AppError
+		require.ErrorAs(t, err, &appErr)
+		assert.Equal(t, []apperror.FieldError{{Field: "tasks[1].type", Rule: "required"}}, appErr.Fields)
+	})
+
+	t.Run("embedded structs are validated", func(t *testing.T) {
+		err := v.Validate(&contracts.UpdateSchedule{
+			CreateSchedule: contracts.CreateSchedule{
+				Name:        "nightly",
+				Title:       "Nightly report",
+				Description: "Builds the report",
+				Type:        "report",
+			},
+		})
+
+		var appErr *apperror.AppError
+		require.ErrorAs(t, err, &appErr)
+		assert.Equal(t, []apperror.FieldError{{Field: "cron", Rule: "required"}}, appErr.Fields)
+	})
+}
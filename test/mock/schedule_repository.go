@@ -0,0 +1,68 @@
+package mock
+
+import (
+	"context"
+	"task-pool/internal/domain/entity"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// ScheduleRepository is a mock implementation of ScheduleRepository for testing using testify/mock
+type ScheduleRepository struct {
+	mock.Mock
+}
+
+// NewScheduleRepository creates a new instance of ScheduleRepository
+func NewScheduleRepository() *ScheduleRepository {
+	return &ScheduleRepository{}
+}
+
+func (m *ScheduleRepository) Create(ctx context.Context, schedule *entity.Schedule) error {
+	args := m.Called(ctx, schedule)
+	return args.Error(0)
+}
+
+func (m *ScheduleRepository) FindByID(ctx context.Context, id uint64) (*entity.Schedule, error) {
+	args := m.Called(ctx, id)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*entity.Schedule), args.Error(1)
+}
+
+func (m *ScheduleRepository) FindAll(ctx context.Context) ([]*entity.Schedule, error) {
+	args := m.Called(ctx)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]*entity.Schedule), args.Error(1)
+}
+
+func (m *ScheduleRepository) Update(ctx context.Context, schedule *entity.Schedule) error {
+	args := m.Called(ctx, schedule)
+	return args.Error(0)
+}
+
+func (m *ScheduleRepository) Delete(ctx context.Context, id uint64) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *ScheduleRepository) FireDue(
+	ctx context.Context,
+	now time.Time,
+	fire func(schedule *entity.Schedule) *entity.Task,
+) ([]*entity.Task, error) {
+	args := m.Called(ctx, now, fire)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]*entity.Task), args.Error(1)
+}
@@ -0,0 +1,38 @@
+package mock
+
+import (
+	"context"
+	"task-pool/internal/domain/entity"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// TaskAttemptRepository is a mock implementation of TaskAttemptRepository for testing using testify/mock
+type TaskAttemptRepository struct {
+	mock.Mock
+}
+
+// NewTaskAttemptRepository creates a new instance of TaskAttemptRepository
+func NewTaskAttemptRepository() *TaskAttemptRepository {
+	return &TaskAttemptRepository{}
+}
+
+func (m *TaskAttemptRepository) Create(ctx context.Context, attempt *entity.TaskAttempt) error {
+	args := m.Called(ctx, attempt)
+	return args.Error(0)
+}
+
+func (m *TaskAttemptRepository) Update(ctx context.Context, attempt *entity.TaskAttempt) error {
+	args := m.Called(ctx, attempt)
+	return args.Error(0)
+}
+
+func (m *TaskAttemptRepository) FindByTaskID(ctx context.Context, taskID uint64) ([]*entity.TaskAttempt, error) {
+	args := m.Called(ctx, taskID)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]*entity.TaskAttempt), args.Error(1)
+}
@@ -3,6 +3,8 @@ package mock
 import (
 	"context"
 	"task-pool/internal/domain/entity"
+	"task-pool/internal/domain/repository"
+	"time"
 
 	"github.com/stretchr/testify/mock"
 )
@@ -22,6 +24,46 @@ func (m *TaskRepository) Create(ctx context.Context, task *entity.Task) error {
 	return args.Error(0)
 }
 
+func (m *TaskRepository) CreateBatch(ctx context.Context, tasks []*entity.Task) error {
+	args := m.Called(ctx, tasks)
+	return args.Error(0)
+}
+
+func (m *TaskRepository) CreateWithDependencies(ctx context.Context, task *entity.Task, dependsOn []uint64) error {
+	args := m.Called(ctx, task, dependsOn)
+	return args.Error(0)
+}
+
+func (m *TaskRepository) ResolveDependents(ctx context.Context, task *entity.Task) ([]*entity.Task, error) {
+	args := m.Called(ctx, task)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]*entity.Task), args.Error(1)
+}
+
+func (m *TaskRepository) ResolveBlocked(ctx context.Context) ([]*entity.Task, error) {
+	args := m.Called(ctx)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]*entity.Task), args.Error(1)
+}
+
+func (m *TaskRepository) FindGraph(ctx context.Context, id uint64) ([]*entity.Task, []*entity.TaskDependency, error) {
+	args := m.Called(ctx, id)
+
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+
+	return args.Get(0).([]*entity.Task), args.Get(1).([]*entity.TaskDependency), args.Error(2)
+}
+
 func (m *TaskRepository) FindByID(ctx context.Context, id uint64) (*entity.Task, error) {
 	args := m.Called(ctx, id)
 
@@ -32,7 +74,72 @@ func (m *TaskRepository) FindByID(ctx context.Context, id uint64) (*entity.Task,
 	return args.Get(0).(*entity.Task), args.Error(1)
 }
 
-func (m *TaskRepository) FindAll(ctx context.Context) ([]*entity.Task, error) {
+func (m *TaskRepository) FindByIdempotencyKey(ctx context.Context, key string) (*entity.Task, error) {
+	args := m.Called(ctx, key)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*entity.Task), args.Error(1)
+}
+
+func (m *TaskRepository) FindAll(ctx context.Context, filter repository.TaskFilter) (*repository.TaskPage, error) {
+	args := m.Called(ctx, filter)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*repository.TaskPage), args.Error(1)
+}
+
+func (m *TaskRepository) Update(ctx context.Context, task *entity.Task, attempt int) error {
+	args := m.Called(ctx, task, attempt)
+	return args.Error(0)
+}
+
+func (m *TaskRepository) UpdateProgress(ctx context.Context, id uint64, progress int, message string) error {
+	args := m.Called(ctx, id, progress, message)
+	return args.Error(0)
+}
+
+func (m *TaskRepository) ClaimNext(ctx context.Context, queue string, aging time.Duration) (*entity.Task, error) {
+	args := m.Called(ctx, queue, aging)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*entity.Task), args.Error(1)
+}
+
+func (m *TaskRepository) Heartbeat(ctx context.Context, id uint64) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *TaskRepository) RecoverAbandoned(ctx context.Context, timeout time.Duration, maxAttempts int) ([]*entity.Task, error) {
+	args := m.Called(ctx, timeout, maxAttempts)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]*entity.Task), args.Error(1)
+}
+
+func (m *TaskRepository) Release(ctx context.Context, ids []uint64) ([]*entity.Task, error) {
+	args := m.Called(ctx, ids)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]*entity.Task), args.Error(1)
+}
+
+func (m *TaskRepository) FindDeadLettered(ctx context.Context) ([]*entity.Task, error) {
 	args := m.Called(ctx)
 
 	if args.Get(0) == nil {
@@ -42,7 +149,52 @@ func (m *TaskRepository) FindAll(ctx context.Context) ([]*entity.Task, error) {
 	return args.Get(0).([]*entity.Task), args.Error(1)
 }
 
-func (m *TaskRepository) Update(ctx context.Context, task *entity.Task) error {
-	args := m.Called(ctx, task)
-	return args.Error(0)
+func (m *TaskRepository) RequeueDeadLettered(ctx context.Context, ids []uint64) ([]*entity.Task, error) {
+	args := m.Called(ctx, ids)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]*entity.Task), args.Error(1)
+}
+
+func (m *TaskRepository) PurgeDeadLettered(ctx context.Context) (int64, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *TaskRepository) Cancel(ctx context.Context, id uint64) (*entity.Task, error) {
+	args := m.Called(ctx, id)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*entity.Task), args.Error(1)
+}
+
+func (m *TaskRepository) FindCancelled(ctx context.Context, ids []uint64) ([]uint64, error) {
+	args := m.Called(ctx, ids)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]uint64), args.Error(1)
+}
+
+func (m *TaskRepository) CountPending(ctx context.Context, queue string) (int64, error) {
+	args := m.Called(ctx, queue)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *TaskRepository) FindNextRunAt(ctx context.Context) (*time.Time, error) {
+	args := m.Called(ctx)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*time.Time), args.Error(1)
 }
@@ -0,0 +1,33 @@
+package mock
+
+import (
+	"context"
+	"task-pool/internal/domain/entity"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// WorkflowRepository is a mock implementation of WorkflowRepository for testing using testify/mock
+type WorkflowRepository struct {
+	mock.Mock
+}
+
+// NewWorkflowRepository creates a new instance of WorkflowRepository
+func NewWorkflowRepository() *WorkflowRepository {
+	return &WorkflowRepository{}
+}
+
+func (m *WorkflowRepository) Create(ctx context.Context, workflow *entity.Workflow) error {
+	args := m.Called(ctx, workflow)
+	return args.Error(0)
+}
+
+func (m *WorkflowRepository) FindByID(ctx context.Context, id uint64) (*entity.Workflow, error) {
+	args := m.Called(ctx, id)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*entity.Workflow), args.Error(1)
+}